@@ -0,0 +1,143 @@
+package easytmpl
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+)
+
+// BatchResolver resolves the values of many placeholder keys at once, e.g. with a single round trip to a backend.
+// Keys that cannot be resolved are left out of the returned map.
+type BatchResolver interface {
+	Resolve(ctx context.Context, keys []string) (map[string]string, error)
+}
+
+// BatchResolverFunc is an adapter to allow the use of ordinary functions as BatchResolver.
+type BatchResolverFunc func(ctx context.Context, keys []string) (map[string]string, error)
+
+// Resolve calls f(ctx, keys).
+func (f BatchResolverFunc) Resolve(ctx context.Context, keys []string) (map[string]string, error) {
+	return f(ctx, keys)
+}
+
+// ExecBatch resolves the distinct placeholder keys of the template with a single call to r,
+// then renders the template to w with the resolved values as ExecString does.
+// It returns TemplateModeError for templates in Mustache mode.
+func (t *Template) ExecBatch(ctx context.Context, w io.Writer, r BatchResolver, strict bool) error {
+	return t.ExecBatchParallel(ctx, w, r, 0, 1, strict)
+}
+
+// ExecBatchParallel is like ExecBatch but splits the keys into batches of at most batchSize keys
+// (all keys in one batch if batchSize <= 0) and runs up to parallelism calls to r concurrently.
+// The first error cancels the context passed to the remaining calls and is returned; nothing is written to w.
+func (t *Template) ExecBatchParallel(ctx context.Context, w io.Writer, r BatchResolver, batchSize, parallelism int, strict bool) (err error) {
+	defer t.wrapError(&err)
+	if t.mustache {
+		return TemplateModeError
+	}
+
+	placeholder := t.Placeholder()
+	t.shellWordKeys(placeholder)
+	keys := make([]string, 0, len(placeholder))
+	for k := range placeholder {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args, err := resolveBatches(ctx, r, keys, batchSize, parallelism)
+	if err != nil {
+		return err
+	}
+
+	if t.syntax == ShellSyntax {
+		return t.execShell(w, func(key string) (string, bool) {
+			v, ok := args[key]
+			return v, ok
+		}, strict)
+	}
+	if strict {
+		for i, a := range t.args {
+			if _, ok := args[b2s(a)]; !ok {
+				return t.missingParameter(i)
+			}
+		}
+	}
+	return t.exec(w, func(w io.Writer, i int) error {
+		return t.execArg(w, i, args)
+	})
+}
+
+// resolveBatches resolves keys with r in batches of batchSize keys, running up to parallelism batches concurrently.
+func resolveBatches(ctx context.Context, r BatchResolver, keys []string, batchSize, parallelism int) (map[string]string, error) {
+	if len(keys) == 0 {
+		return map[string]string{}, nil
+	}
+	if batchSize <= 0 || batchSize >= len(keys) {
+		return r.Resolve(ctx, keys)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		args     = make(map[string]string, len(keys))
+		sem      = make(chan struct{}, max(parallelism, 1))
+	)
+	for start := 0; start < len(keys); start += batchSize {
+		// the full slice expression keeps a resolver appending to its batch from overwriting the next one.
+		end := min(start+batchSize, len(keys))
+		batch := keys[start:end:end]
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			values, err := r.Resolve(ctx, batch)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for k, v := range values {
+				args[k] = v
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// shellWordKeys adds the parameters referenced in the words of shell expansions, e.g. B in `${A:-$B}`, to keys.
+func (t *Template) shellWordKeys(keys map[string]int) {
+	for _, exp := range t.shell {
+		if exp.word == nil {
+			continue
+		}
+		for k, n := range exp.word.Placeholder() {
+			keys[k] += n
+		}
+		exp.word.shellWordKeys(keys)
+	}
+}
@@ -0,0 +1,136 @@
+package easytmpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTemplate_ExecBatch(t *testing.T) {
+	template, err := NewTemplate("{{a}} {{b}} {{a}} {{c}}")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	t.Run("case:single batch", func(t *testing.T) {
+		var calls [][]string
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			calls = append(calls, keys)
+			return map[string]string{"a": "1", "b": "2", "c": "3"}, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatch(context.Background(), &bb, r, true); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "1 2 1 3"; bb.String() != want {
+			t.Errorf("got %q  want:%q", bb.String(), want)
+		}
+		if want := [][]string{{"a", "b", "c"}}; !reflect.DeepEqual(calls, want) {
+			t.Errorf("got %v  want:%v", calls, want)
+		}
+	})
+
+	t.Run("case:bounded parallelism", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			seen     []string
+			inflight atomic.Int32
+			peak     atomic.Int32
+		)
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			n := inflight.Add(1)
+			defer inflight.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			mu.Lock()
+			seen = append(seen, keys...)
+			mu.Unlock()
+			values := make(map[string]string, len(keys))
+			for _, k := range keys {
+				values[k] = k + k
+			}
+			return values, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatchParallel(context.Background(), &bb, r, 1, 2, true); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "aa bb aa cc"; bb.String() != want {
+			t.Errorf("got %q  want:%q", bb.String(), want)
+		}
+		sort.Strings(seen)
+		if want := []string{"a", "b", "c"}; !reflect.DeepEqual(seen, want) {
+			t.Errorf("got %v  want:%v", seen, want)
+		}
+		if peak.Load() > 2 {
+			t.Errorf("got %d concurrent calls, want at most 2", peak.Load())
+		}
+	})
+
+	t.Run("case:resolver appending to its keys", func(t *testing.T) {
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			keys = append(keys, "x")
+			values := make(map[string]string, len(keys))
+			for _, k := range keys {
+				values[k] = k + k
+			}
+			return values, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatchParallel(context.Background(), &bb, r, 1, 1, true); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "aa bb aa cc"; bb.String() != want {
+			t.Errorf("got %q  want:%q", bb.String(), want)
+		}
+	})
+
+	t.Run("case:resolver error", func(t *testing.T) {
+		want := errors.New("backend down")
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			if keys[0] == "b" {
+				return nil, want
+			}
+			return map[string]string{keys[0]: "x"}, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatchParallel(context.Background(), &bb, r, 1, 1, false); err != want {
+			t.Errorf("got %v  want:%v", err, want)
+		}
+		if bb.Len() != 0 {
+			t.Errorf("got output %q", bb.String())
+		}
+	})
+
+	t.Run("case:strict mode", func(t *testing.T) {
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			return map[string]string{"a": "1"}, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatch(context.Background(), &bb, r, true); !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Errorf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:shell syntax resolves words", func(t *testing.T) {
+		template, err := NewTemplate("${A:-$B}", WithSyntax(ShellSyntax))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		r := BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			if want := []string{"A", "B"}; !reflect.DeepEqual(keys, want) {
+				t.Errorf("got %v  want:%v", keys, want)
+			}
+			return map[string]string{"B": "b"}, nil
+		})
+		var bb bytes.Buffer
+		if err := template.ExecBatch(context.Background(), &bb, r, false); err != nil || bb.String() != "b" {
+			t.Errorf("got %q, %v", bb.String(), err)
+		}
+	})
+}
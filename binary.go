@@ -0,0 +1,384 @@
+package easytmpl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	// TemplateBinaryFormatError indicates that the data passed to UnmarshalBinary is truncated or corrupted.
+	TemplateBinaryFormatError = errors.New("invalid template binary data")
+
+	// TemplateBinaryVersionError indicates that the data passed to UnmarshalBinary was written in a format version
+	// this package does not support.
+	TemplateBinaryVersionError = errors.New("unsupported template binary format version")
+
+	// TemplateBoundSecretError indicates that a template holding secret values bound with Bind cannot be serialized.
+	TemplateBoundSecretError = errors.New("template holds bound secret values")
+)
+
+// binaryMagic starts the binary form of a template; it is followed by binaryFormatVersion.
+const binaryMagic = "etpl"
+
+// binaryFormatVersion is the version of the binary form written by MarshalBinary.
+// It must be incremented whenever the layout changes.
+const binaryFormatVersion = 1
+
+// binary flags.
+const (
+	binaryMustache = 1 << iota
+	binaryAutoFill
+	binaryFormats
+	binaryPositions
+	binaryStripped
+	binaryIndent
+	binaryLists
+	binaryFormatSpecs
+	binaryListSpecs
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The binary form holds a format version header, the content,
+// the parsed intervals and placeholder keys, the tag pair and the options, so that UnmarshalBinary restores the
+// template without parsing it again. Constraints set with WithPattern or WithCharClass and hooks are not persisted.
+// It returns TemplateBoundSecretError if the template holds secret values bound with Bind.
+func (t *Template) MarshalBinary() ([]byte, error) {
+	if len(t.redactions) > 0 {
+		return nil, TemplateBoundSecretError
+	}
+	c := configOf(t)
+
+	var flags uint64
+	if c.Mustache {
+		flags |= binaryMustache
+	}
+	if c.AutoFill != nil {
+		flags |= binaryAutoFill
+	}
+	compiled := !c.Mustache && c.Syntax == TagSyntax
+	if compiled && t.formats != nil {
+		flags |= binaryFormats
+	}
+	if compiled && t.positions != nil {
+		flags |= binaryPositions
+	}
+	if compiled && t.source != nil {
+		flags |= binaryStripped
+	}
+	if compiled && t.lists != nil {
+		flags |= binaryLists
+	}
+	if c.Indent {
+		flags |= binaryIndent
+	}
+	if c.FormatSpecs {
+		flags |= binaryFormatSpecs
+	}
+	if c.ListSpecs {
+		flags |= binaryListSpecs
+	}
+
+	b := make([]byte, 0, len(binaryMagic)+1+len(t.content)*2)
+	b = append(b, binaryMagic...)
+	b = append(b, binaryFormatVersion)
+	b = binary.AppendUvarint(b, flags)
+	for _, s := range []string{c.Name, c.Version, c.Checksum, c.Start, c.End, c.Content} {
+		b = appendString(b, s)
+	}
+	if c.AutoFill != nil {
+		b = appendString(b, *c.AutoFill)
+	}
+	for _, n := range []int{c.Capacity, int(c.Syntax), c.MaxTemplateSize, c.MaxOutputSize, c.MaxValueSize} {
+		b = binary.AppendUvarint(b, uint64(n))
+	}
+	partials := make([]string, 0, len(c.Partials))
+	for k := range c.Partials {
+		partials = append(partials, k)
+	}
+	sort.Strings(partials)
+	b = binary.AppendUvarint(b, uint64(len(partials)))
+	for _, k := range partials {
+		b = appendString(b, k)
+		b = appendString(b, c.Partials[k])
+	}
+	b = binary.AppendUvarint(b, uint64(len(c.SecretKeys)))
+	for _, k := range c.SecretKeys {
+		b = appendString(b, k)
+	}
+	b = binary.AppendUvarint(b, uint64(len(c.IndentKeys)))
+	for _, k := range c.IndentKeys {
+		b = appendString(b, k)
+	}
+
+	// Mustache and ShellSyntax templates are parsed again by UnmarshalBinary.
+	if !compiled {
+		return b, nil
+	}
+	// the content without comments and raw block tags, which the intervals refer to.
+	if t.source != nil {
+		b = appendString(b, b2s(t.content))
+	}
+	b = binary.AppendUvarint(b, uint64(len(t.contentIntervalIdx)))
+	for i, iv := range t.contentIntervalIdx {
+		b = binary.AppendUvarint(b, uint64(iv[0]))
+		if i < len(t.args) {
+			b = binary.AppendUvarint(b, uint64(iv[1]))
+		}
+	}
+	// keys are written as a range of their placeholder, so that they share the content's memory once decoded.
+	for i, a := range t.args {
+		k := bytes.Index(t.placeholder(i), a)
+		if k < 0 {
+			return nil, TemplateBinaryFormatError
+		}
+		b = binary.AppendUvarint(b, uint64(k))
+		b = binary.AppendUvarint(b, uint64(len(a)))
+		if t.formats != nil {
+			b = appendString(b, t.formats[i].verb)
+			b = appendString(b, t.formats[i].layout)
+		}
+		if t.positions != nil {
+			b = binary.AppendVarint(b, int64(t.positions[i]))
+		}
+		if t.lists != nil {
+			query := uint64(0)
+			if t.lists[i].query {
+				query = 1
+			}
+			b = appendString(b, t.lists[i].sep)
+			b = binary.AppendUvarint(b, query)
+		}
+	}
+	return b, nil
+}
+
+// appendString appends s to b, prefixed with its length.
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// binaryReader decodes the binary form of a template. The first decoding error is kept in err;
+// later reads return zero values.
+type binaryReader struct {
+	b   []byte
+	err error
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = TemplateBinaryFormatError
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// int reads a non-negative int.
+func (r *binaryReader) int() int {
+	v := r.uvarint()
+	if v > math.MaxInt {
+		r.err = TemplateBinaryFormatError
+		return 0
+	}
+	return int(v)
+}
+
+func (r *binaryReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = TemplateBinaryFormatError
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// bytes reads a length-prefixed byte slice; it shares the memory of the data being decoded.
+func (r *binaryReader) bytes() []byte {
+	n := r.int()
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.b) {
+		r.err = TemplateBinaryFormatError
+		return nil
+	}
+	v := r.b[:n:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *binaryReader) string() string {
+	return b2s(r.bytes())
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It restores a template written by MarshalBinary
+// without parsing the content again, except for templates in Mustache mode or in ShellSyntax.
+// Hooks and constraints are not persisted; those already set on t are kept.
+func (t *Template) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return TemplateBinaryFormatError
+	}
+	if data[len(binaryMagic)] != binaryFormatVersion {
+		return TemplateBinaryVersionError
+	}
+	// data is copied: the decoded template keeps slices of it.
+	r := &binaryReader{b: append([]byte(nil), data[len(binaryMagic)+1:]...)}
+
+	flags := r.uvarint()
+	var c templateConfig
+	c.Mustache = flags&binaryMustache != 0
+	c.Indent = flags&binaryIndent != 0
+	c.FormatSpecs = flags&binaryFormatSpecs != 0
+	c.ListSpecs = flags&binaryListSpecs != 0
+	c.Name, c.Version, c.Checksum, c.Start, c.End = r.string(), r.string(), r.string(), r.string(), r.string()
+	content := r.bytes()
+	if flags&binaryAutoFill != 0 {
+		s := r.string()
+		c.AutoFill = &s
+	}
+	c.Capacity, c.Syntax = r.int(), Syntax(r.int())
+	c.MaxTemplateSize, c.MaxOutputSize, c.MaxValueSize = r.int(), r.int(), r.int()
+	if n := r.int(); n > 0 && r.err == nil {
+		c.Partials = make(map[string]string, min(n, len(r.b)))
+		for i := 0; i < n && r.err == nil; i++ {
+			k := r.string()
+			c.Partials[k] = r.string()
+		}
+	}
+	for n, i := r.int(), 0; i < n && r.err == nil; i++ {
+		c.SecretKeys = append(c.SecretKeys, r.string())
+	}
+	for n, i := r.int(), 0; i < n && r.err == nil; i++ {
+		c.IndentKeys = append(c.IndentKeys, r.string())
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	nt := &Template{content: content, hooks: t.hooks, constraints: t.constraints}
+	for _, opt := range c.options(nil) {
+		if err := opt(nt); err != nil {
+			return err
+		}
+	}
+
+	if c.Mustache || c.Syntax != TagSyntax {
+		if err := nt.compile(); err != nil {
+			return err
+		}
+	} else if err := nt.decodeParsed(r, flags); err != nil {
+		return err
+	}
+	if nt.checksum != c.Checksum {
+		return TemplateBinaryFormatError
+	}
+	*t = *nt
+	return nil
+}
+
+// decodeParsed restores the intervals, keys, format specs and positions written by MarshalBinary,
+// checking that the intervals fit the content.
+func (t *Template) decodeParsed(r *binaryReader, flags uint64) error {
+	if flags&binaryStripped != 0 {
+		t.source, t.content = t.content, r.bytes()
+		// the offsets of the source are not written; they are found again by scanning the source.
+		d, err := t.scanDirectives(t.source)
+		if err != nil || !bytes.Equal(d.content, t.content) {
+			return TemplateBinaryFormatError
+		}
+		t.sourceOffsets = d.offsets
+	}
+	n := r.int()
+	if r.err != nil || n == 0 || n > len(r.b) {
+		return TemplateBinaryFormatError
+	}
+	t.contentIntervalIdx = make([][2]int, n)
+	last := 0
+	for i := range t.contentIntervalIdx {
+		t.contentIntervalIdx[i][0] = r.int()
+		t.contentIntervalIdx[i][1] = math.MaxInt
+		if i < n-1 {
+			t.contentIntervalIdx[i][1] = r.int()
+		}
+		if t.contentIntervalIdx[i][0] < last || t.contentIntervalIdx[i][1] < t.contentIntervalIdx[i][0] ||
+			t.contentIntervalIdx[i][0] > len(t.content) || (i < n-1 && t.contentIntervalIdx[i][1] > len(t.content)) {
+			return TemplateBinaryFormatError
+		}
+		last = t.contentIntervalIdx[i][1]
+	}
+
+	t.args = make([][]byte, n-1)
+	if flags&binaryFormats != 0 {
+		t.formats = make([]valueFormat, n-1)
+	}
+	if flags&binaryPositions != 0 {
+		t.positions = make([]int, n-1)
+	}
+	if flags&binaryLists != 0 {
+		t.lists = make([]listSpec, n-1)
+	}
+	for i := range t.args {
+		placeholder := t.placeholder(i)
+		k, n := r.int(), r.int()
+		if k > len(placeholder) || n > len(placeholder)-k {
+			return TemplateBinaryFormatError
+		}
+		t.args[i] = placeholder[k : k+n : k+n]
+		if t.formats != nil {
+			verb, layout := r.string(), r.string()
+			t.formats[i] = newValueFormat(verb + layout)
+		}
+		if t.positions != nil {
+			p := r.varint()
+			if p < -1 || p > math.MaxInt32 {
+				return TemplateBinaryFormatError
+			}
+			t.positions[i] = int(p)
+			t.positional = max(t.positional, t.positions[i]+1)
+		}
+		if t.lists != nil {
+			t.lists[i] = listSpec{sep: r.string(), query: r.uvarint() == 1}
+		}
+	}
+	if r.err != nil || len(r.b) != 0 {
+		return TemplateBinaryFormatError
+	}
+	t.checksum = checksumOf(t.sourceText())
+	t.parseIndents()
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the template source.
+// It returns TemplateBoundSecretError if the template holds secret values bound with Bind.
+func (t *Template) MarshalText() ([]byte, error) {
+	if len(t.redactions) > 0 {
+		return nil, TemplateBoundSecretError
+	}
+	return append([]byte(nil), t.sourceText()...), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses text as the template source, keeping the options
+// already set on t; a zero Template gets the defaults of NewTemplate.
+func (t *Template) UnmarshalText(text []byte) (err error) {
+	defer t.wrapError(&err)
+	if err := checkContent(b2s(text)); err != nil {
+		return err
+	}
+	nt, err := t.recompile(append([]byte(nil), text...))
+	if err != nil {
+		return err
+	}
+	*t = *nt
+	return nil
+}
@@ -0,0 +1,141 @@
+package easytmpl
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Template)(nil)
+	_ encoding.BinaryUnmarshaler = (*Template)(nil)
+	_ encoding.TextMarshaler     = (*Template)(nil)
+	_ encoding.TextUnmarshaler   = (*Template)(nil)
+)
+
+func TestTemplate_MarshalBinary(t *testing.T) {
+	cases := []struct {
+		name string
+		tpl  string
+		opts []OptionHandler
+		args map[string]string
+	}{
+		{"tags", "Hello {{name}}, {{ greeting }}!", nil, map[string]string{"name": "Ada"}},
+		{"tag pair and options", "[[a]]-[[b]]-[[0]]", []OptionHandler{WithTagPair("[[", "]]"), WithAutoFill("?"), WithName("n"), WithVersion("v1"), WithSecretKeys("a"), WithMaxOutputSize(64)}, map[string]string{"a": "x"}},
+		{"formats", "{{price:%.2f}} at {{ts:2006-01-02}}", nil, map[string]string{"price": "1"}},
+		{"no placeholders", "static", nil, nil},
+		{"mustache", "{{#items}}<{{.}}>{{/items}}{{> p}}", []OptionHandler{WithMustache(), WithPartials(map[string]string{"p": "!"})}, nil},
+		{"shell", "${HOME:-/root} $USER", []OptionHandler{WithSyntax(ShellSyntax)}, map[string]string{"USER": "ada"}},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := NewTemplate(c.tpl, c.opts...)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			data, err := template.MarshalBinary()
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			var got Template
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if !reflect.DeepEqual(got.Placeholder(), template.Placeholder()) {
+				t.Errorf("got %v  want:%v", got.Placeholder(), template.Placeholder())
+			}
+			if got.Checksum() != template.Checksum() || got.Name() != template.Name() || got.Version() != template.Version() {
+				t.Errorf("got %v %v %v  want:%v %v %v", got.Checksum(), got.Name(), got.Version(), template.Checksum(), template.Name(), template.Version())
+			}
+			want, wantErr := template.ExecString(c.args, false)
+			s, err := got.ExecString(c.args, false)
+			if s != want || !errors.Is(err, wantErr) {
+				t.Errorf("got %q %v  want:%q %v", s, err, want, wantErr)
+			}
+			if !template.mustache && template.syntax == TagSyntax && !reflect.DeepEqual(got.contentIntervalIdx, template.contentIntervalIdx) {
+				t.Errorf("got %v  want:%v", got.contentIntervalIdx, template.contentIntervalIdx)
+			}
+		})
+	}
+
+	t.Run("case:typed values", func(t *testing.T) {
+		template, _ := NewTemplate("{{price:%.2f}} on {{day:2006-01-02}} #{{1}}", WithFormatSpecs())
+		data, _ := template.MarshalBinary()
+		var got Template
+		if err := got.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		s, err := got.ExecValues(map[string]any{"price": 2.5, "day": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), "1": 7}, true)
+		if want := "2.50 on 2024-05-01 #7"; s != want || err != nil {
+			t.Errorf("got %q %v  want:%q", s, err, want)
+		}
+		if got.positional != template.positional || !got.formatSpecs {
+			t.Errorf("got %v %v  want:%v true", got.positional, got.formatSpecs, template.positional)
+		}
+	})
+
+	t.Run("case:invalid data", func(t *testing.T) {
+		template, _ := NewTemplate("a {{b}} c {{d}}")
+		data, _ := template.MarshalBinary()
+		var got Template
+		for i := 0; i < len(data); i++ {
+			if err := got.UnmarshalBinary(data[:i]); err == nil {
+				t.Fatalf("got nil error for %d bytes", i)
+			}
+		}
+		corrupted := append([]byte(nil), data...)
+		corrupted[bytes.Index(corrupted, []byte("a {{b}}"))] = 'x'
+		if err := got.UnmarshalBinary(corrupted); !errors.Is(err, TemplateBinaryFormatError) {
+			t.Errorf("got %v  want:%v", err, TemplateBinaryFormatError)
+		}
+		corrupted = append([]byte(nil), data...)
+		corrupted[len(binaryMagic)] = binaryFormatVersion + 1
+		if err := got.UnmarshalBinary(corrupted); !errors.Is(err, TemplateBinaryVersionError) {
+			t.Errorf("got %v  want:%v", err, TemplateBinaryVersionError)
+		}
+	})
+
+	t.Run("case:bound secret", func(t *testing.T) {
+		template, _ := NewTemplate("{{secret.key}} {{a}}")
+		bound := template.Bind(map[string]string{"secret.key": "k"})
+		if _, err := bound.MarshalBinary(); !errors.Is(err, TemplateBoundSecretError) {
+			t.Errorf("got %v  want:%v", err, TemplateBoundSecretError)
+		}
+		if _, err := bound.MarshalText(); !errors.Is(err, TemplateBoundSecretError) {
+			t.Errorf("got %v  want:%v", err, TemplateBoundSecretError)
+		}
+	})
+}
+
+func TestTemplate_MarshalText(t *testing.T) {
+	template, _ := NewTemplate("<%a%> {{b}}", WithTagPair("<%", "%>"))
+	text, err := template.MarshalText()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if string(text) != "<%a%> {{b}}" {
+		t.Errorf("got %s  want:%s", text, "<%a%> {{b}}")
+	}
+
+	var zero Template
+	if err := zero.UnmarshalText(text); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := map[string]int{"b": 1}; !reflect.DeepEqual(zero.Placeholder(), want) {
+		t.Errorf("got %v  want:%v", zero.Placeholder(), want)
+	}
+
+	configured, _ := NewTemplate("x", WithTagPair("<%", "%>"))
+	if err := configured.UnmarshalText(text); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := map[string]int{"a": 1}; !reflect.DeepEqual(configured.Placeholder(), want) {
+		t.Errorf("got %v  want:%v", configured.Placeholder(), want)
+	}
+	if err := configured.UnmarshalText([]byte("  ")); !errors.Is(err, TemplateContentEmptyError) {
+		t.Errorf("got %v  want:%v", err, TemplateContentEmptyError)
+	}
+}
@@ -0,0 +1,103 @@
+package easytmpl
+
+import (
+	"math"
+)
+
+// Bind substitutes the placeholders whose keys are present in args and returns a new compiled template
+// in which only the remaining placeholders are left. The tag pair, syntax and options of t are preserved,
+// and the result is built from the already parsed intervals without parsing the content again.
+// Bound values are formatted according to the placeholder's format spec, as in ExecString.
+//
+// In ShellSyntax, an expansion is bound when its parameter is present in args, and so are the parameters of
+// the word its operator expands, e.g. HOST and PATH for `${HOST:+https://$HOST/$PATH}` with a non-empty HOST.
+// Other expansions are kept, as are expansions that fail, e.g. `${VAR:?word}` with an empty value.
+// Values of secret placeholders are redacted in the String form of the new template.
+// Comments and raw block tags, already dropped from the content of t, are not part of the new template's source.
+// Templates in Mustache mode are returned unchanged.
+func (t *Template) Bind(args map[string]string) *Template {
+	if t.mustache {
+		return t
+	}
+
+	nt := *t
+	nt.contentIntervalIdx = nil
+	nt.args, nt.formats, nt.lists, nt.positions, nt.shell = nil, nil, nil, nil, nil
+	nt.positional = 0
+	nt.redactions = nil
+
+	// missed records whether an expansion looked up a parameter missing from args.
+	var missed bool
+	lookup := func(key string) (string, bool) {
+		v, ok := args[key]
+		missed = missed || !ok
+		return v, ok
+	}
+	w := &appendWriter{b: make([]byte, 0, len(t.content))}
+	// static copies the static content [from, to) of t, keeping track of the secret values bound into it.
+	static := func(from, to int) {
+		for _, r := range t.redactions {
+			if from <= r[0] && r[1] <= to {
+				nt.redactions = append(nt.redactions, [2]int{r[0] - from + len(w.b), r[1] - from + len(w.b)})
+			}
+		}
+		w.b = append(w.b, t.content[from:to]...)
+	}
+
+	var (
+		iw      = t.newIndentWriter()
+		scratch []byte
+	)
+	start := 0
+	for i := 0; i < len(t.contentIntervalIdx)-1; i++ {
+		static(t.contentIntervalIdx[i][0], t.contentIntervalIdx[i][1])
+
+		if v, ok := args[b2s(t.args[i])]; ok {
+			n := len(w.b)
+			pw := t.placeholderWriter(iw, w, i)
+			var err error
+			if t.syntax != ShellSyntax {
+				scratch = t.appendArg(scratch[:0], i, v)
+				_, err = pw.Write(scratch)
+			} else {
+				missed = false
+				err = t.expandShell(pw, i, lookup, false)
+			}
+			if err == nil && !missed {
+				if len(w.b) > n && t.hasSecret(i) {
+					nt.redactions = append(nt.redactions, [2]int{n, len(w.b)})
+				}
+				continue
+			}
+			w.b = w.b[:n]
+		}
+
+		nt.contentIntervalIdx = append(nt.contentIntervalIdx, [2]int{start, len(w.b)})
+		w.b = append(w.b, t.placeholder(i)...)
+		start = len(w.b)
+		nt.args = append(nt.args, t.args[i])
+		if t.formats != nil {
+			nt.formats = append(nt.formats, t.formats[i])
+		}
+		if t.lists != nil {
+			nt.lists = append(nt.lists, t.lists[i])
+		}
+		if t.shell != nil {
+			nt.shell = append(nt.shell, t.shell[i])
+		}
+		if t.positions != nil {
+			nt.positions = append(nt.positions, t.positions[i])
+			nt.positional = max(nt.positional, t.positions[i]+1)
+		}
+	}
+	if nt.positional == 0 {
+		nt.positions = nil
+	}
+	static(t.contentIntervalIdx[len(t.contentIntervalIdx)-1][0], len(t.content))
+	nt.contentIntervalIdx = append(nt.contentIntervalIdx, [2]int{start, math.MaxInt})
+	nt.content = w.b
+	nt.source, nt.sourceOffsets = nil, nil
+	nt.checksum = checksumOf(nt.content)
+	nt.parseIndents()
+	return &nt
+}
@@ -0,0 +1,94 @@
+package easytmpl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_Bind(t *testing.T) {
+	t.Run("case:staged rendering", func(t *testing.T) {
+		template, err := NewTemplate("https://{{tenant}}.example.com/{{path}}?uid={{uid}}&tenant={{tenant}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		bound := template.Bind(map[string]string{"tenant": "acme"})
+		if want := map[string]int{"path": 1, "uid": 1}; !reflect.DeepEqual(bound.Placeholder(), want) {
+			t.Errorf("got %v  want:%v", bound.Placeholder(), want)
+		}
+		got, err := bound.ExecString(map[string]string{"path": "users", "uid": "42"}, true)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "https://acme.example.com/users?uid=42&tenant=acme"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+		// the original template is unchanged.
+		if got := len(template.Placeholder()); got != 3 {
+			t.Errorf("got %v  want:%v", got, 3)
+		}
+	})
+
+	t.Run("case:tag pair, format specs and positions are preserved", func(t *testing.T) {
+		template, err := NewTemplate("[[a]]-[[1:%3s]]-[[0]]-[[b]]", WithTagPair("[[", "]]"), WithAutoFill("?"), WithFormatSpecs())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		bound := template.Bind(map[string]string{"a": "A", "0": "zero"})
+		got, err := bound.ExecArgs("x", "y")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "A-  y-zero-?"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+		if bound.positional != 2 {
+			t.Errorf("got %v  want:%v", bound.positional, 2)
+		}
+	})
+
+	t.Run("case:bind every placeholder", func(t *testing.T) {
+		template, err := NewTemplate("{{a}}{{b}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		bound := template.Bind(map[string]string{"a": "1", "b": "2"})
+		got, err := bound.ExecString(nil, true)
+		if err != nil || got != "12" {
+			t.Errorf("got %q, %v", got, err)
+		}
+	})
+
+	t.Run("case:shell syntax", func(t *testing.T) {
+		template, err := NewTemplate("${HOST:-localhost}:${PORT:-80} ${NAME:?}", WithSyntax(ShellSyntax))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		bound := template.Bind(map[string]string{"HOST": "", "NAME": ""})
+		got, err := bound.ExecString(map[string]string{"NAME": "web"}, false)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "localhost:80 web"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:shell word with unbound parameters", func(t *testing.T) {
+		template, err := NewTemplate("url=${HOST:+https://$HOST/$PATH} port=${PORT:-$DEFAULT}", WithSyntax(ShellSyntax))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		bound := template.Bind(map[string]string{"HOST": "example.com", "PORT": "8080"})
+		if want := "url=${HOST:+https://$HOST/$PATH} port=8080"; bound.String() != want {
+			t.Errorf("got %q  want:%q", bound.String(), want)
+		}
+		// HOST is no longer bound: the expansion is resolved with the arguments of the last render only.
+		if got, _ := bound.ExecString(map[string]string{"PATH": "api"}, true); got != "url= port=8080" {
+			t.Errorf("got %q  want:%q", got, "url= port=8080")
+		}
+		got, err := bound.ExecString(map[string]string{"HOST": "example.com", "PATH": "api"}, true)
+		if want := "url=https://example.com/api port=8080"; err != nil || got != want {
+			t.Errorf("got %q %v  want:%q", got, err, want)
+		}
+	})
+}
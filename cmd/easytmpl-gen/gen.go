@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+// templateFile is a template to generate a renderer for.
+type templateFile struct {
+	Name    string
+	Content string
+}
+
+// field is a field of a generated struct, holding the value of a placeholder.
+type field struct {
+	Name string
+	Key  string
+	Type string
+}
+
+// generate returns the formatted source of package pkg with a renderer for each of files.
+// The templates are parsed with the tag pair start and end, and opts, e.g. easytmpl.WithFormatSpecs.
+func generate(pkg string, files []templateFile, start, end string, opts ...easytmpl.OptionHandler) ([]byte, error) {
+	var (
+		body    bytes.Buffer
+		imports = map[string]bool{"io": true}
+		types   = make(map[string]string, len(files))
+	)
+	for _, f := range files {
+		name := identifier(strings.TrimSuffix(filepath.Base(f.Name), filepath.Ext(f.Name)), "Template")
+		if other, ok := types[name]; ok {
+			return nil, fmt.Errorf("%s and %s both generate type %s", other, f.Name, name)
+		}
+		types[name] = f.Name
+		if err := generateTemplate(&body, imports, name, f, start, end, opts); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by easytmpl-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"fmt", "io", "net/url", "strings", "time"} {
+		if imports[imp] {
+			fmt.Fprintf(&src, "\t%q\n", imp)
+		}
+	}
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// generateTemplate writes the struct and the Render method of the template f to w, recording the packages
+// they use in imports.
+func generateTemplate(w *bytes.Buffer, imports map[string]bool, name string, f templateFile, start, end string,
+	opts []easytmpl.OptionHandler) error {
+	t, err := easytmpl.NewTemplate(f.Content, append([]easytmpl.OptionHandler{easytmpl.WithTagPair(start, end)}, opts...)...)
+	if err != nil {
+		return err
+	}
+	// rendering without arguments writes the static content, and the placeholders as they are;
+	// the source map tells them apart.
+	out, spans, err := t.ExecWithSourceMap(nil, false)
+	if err != nil {
+		return err
+	}
+
+	var (
+		fields []field
+		byKey  = make(map[string]field, len(t.Placeholder()))
+		names  = make(map[string]bool, len(t.Placeholder()))
+		parts  []string
+	)
+	for _, span := range spans {
+		if span.Kind == easytmpl.StaticSpan {
+			parts = append(parts, strconv.Quote(out[span.Start:span.End]))
+			continue
+		}
+		// the placeholder is, with list specs, `?key` or `key,sep`, or, with format specs, `key:spec`, between the tags.
+		inner := out[span.Start+len(start) : span.End-len(end)]
+		query := strings.HasPrefix(inner, "?"+span.Key)
+		if query {
+			inner = inner[1:]
+		}
+		rest := inner[len(span.Key):]
+		spec, hasSpec, sep, list := "", false, "", false
+		switch {
+		case strings.HasPrefix(rest, ":"):
+			spec, hasSpec = rest[1:], true
+		case !query && strings.HasPrefix(rest, ","):
+			sep, list = rest[1:], true
+			if sep == "" {
+				sep = ","
+			}
+		}
+
+		typ := "string"
+		switch {
+		case list:
+			typ = "[]string"
+		case !hasSpec:
+		case strings.HasPrefix(spec, "%"):
+			typ = verbType(spec[len(spec)-1])
+		default:
+			typ = "time.Time"
+		}
+		fd, ok := byKey[span.Key]
+		if !ok {
+			fd = field{Name: identifier(span.Key, "Arg"), Key: span.Key, Type: typ}
+			for base, i := fd.Name, 2; names[fd.Name]; i++ {
+				fd.Name = base + strconv.Itoa(i)
+			}
+			names[fd.Name] = true
+			byKey[span.Key] = fd
+			fields = append(fields, fd)
+		} else if fd.Type != typ {
+			return fmt.Errorf("placeholder %q is used with different format specs", span.Key)
+		}
+
+		expr := "t." + fd.Name
+		switch typ {
+		case "[]string":
+			expr = fmt.Sprintf("strings.Join(t.%s, %q)", fd.Name, sep)
+			imports["strings"] = true
+		case "time.Time":
+			expr = fmt.Sprintf("t.%s.Format(%q)", fd.Name, spec)
+			imports["time"] = true
+		}
+		if hasSpec && strings.HasPrefix(spec, "%") {
+			expr = fmt.Sprintf("fmt.Sprintf(%q, t.%s)", spec, fd.Name)
+			imports["fmt"] = true
+		}
+		// a query placeholder renders `key=value`, escaped as ExecString does.
+		if query {
+			expr = fmt.Sprintf("url.Values{%q: {%s}}.Encode()", span.Key, expr)
+			imports["net/url"] = true
+		}
+		parts = append(parts, expr)
+	}
+
+	fmt.Fprintf(w, "\n// %s renders %s.\ntype %s struct {\n", name, filepath.Base(f.Name), name)
+	for _, fd := range fields {
+		fmt.Fprintf(w, "\t// %s is the value of the placeholder %q.\n\t%s %s\n", fd.Name, fd.Key, fd.Name, fd.Type)
+	}
+	fmt.Fprintf(w, "}\n\n// Render writes the template to w.\nfunc (t *%s) Render(w io.Writer) error {\n", name)
+	if len(parts) > 0 {
+		fmt.Fprintf(w, "\tfor _, s := range [...]string{\n")
+		for _, p := range parts {
+			fmt.Fprintf(w, "\t\t%s,\n", p)
+		}
+		fmt.Fprintf(w, "\t} {\n\t\tif _, err := io.WriteString(w, s); err != nil {\n\t\t\treturn err\n\t\t}\n\t}\n")
+	}
+	fmt.Fprintf(w, "\treturn nil\n}\n")
+	return nil
+}
+
+// verbType returns the type of the field of a placeholder formatted with the fmt verb, the type ExecString
+// parses string values into for the verb: float64 for `%f`, int64 for `%d` and bool for `%t`.
+// Other verbs, such as `%s`, `%q` and `%v`, format a string.
+func verbType(verb byte) string {
+	switch verb {
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return "float64"
+	case 'd', 'b', 'o', 'O', 'x', 'X', 'c', 'U':
+		return "int64"
+	case 't':
+		return "bool"
+	}
+	return "string"
+}
+
+// identifier converts s to an exported Go identifier, e.g. "user_name" to "UserName".
+// prefix is prepended if s does not start with a letter.
+func identifier(s, prefix string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	id := sb.String()
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) {
+		id = prefix + id
+	}
+	return id
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate(t *testing.T) {
+	for _, name := range []string{"welcome_email", "search_url"} {
+		t.Run("case:"+name, func(t *testing.T) {
+			content, err := os.ReadFile("testdata/" + name + ".tmpl")
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			src, err := generate("mail", []templateFile{{Name: "testdata/" + name + ".tmpl", Content: string(content)}}, "{{", "}}", easytmpl.WithFormatSpecs(), easytmpl.WithListSpecs())
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+
+			if *update {
+				if err := os.WriteFile("testdata/"+name+".golden", src, 0o644); err != nil {
+					t.Fatalf("error %v", err)
+				}
+			}
+			golden, err := os.ReadFile("testdata/" + name + ".golden")
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if string(src) != string(golden) {
+				t.Errorf("got\n%s\nwant:\n%s", src, golden)
+			}
+
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "gen.go", src, 0)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+			if _, err := conf.Check("mail", fset, []*ast.File{file}, nil); err != nil {
+				t.Errorf("generated code does not compile: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerate_receipt(t *testing.T) {
+	// internal/receipt holds the renderer generated for receipt.tmpl; its test compares it with ExecString.
+	content, err := os.ReadFile("internal/receipt/receipt.tmpl")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	src, err := generate("receipt", []templateFile{{Name: "receipt.tmpl", Content: string(content)}}, "{{", "}}",
+		easytmpl.WithFormatSpecs(), easytmpl.WithListSpecs())
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if *update {
+		if err := os.WriteFile("internal/receipt/receipt_gen.go", src, 0o644); err != nil {
+			t.Fatalf("error %v", err)
+		}
+	}
+	golden, err := os.ReadFile("internal/receipt/receipt_gen.go")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if string(src) != string(golden) {
+		t.Errorf("got\n%s\nwant:\n%s", src, golden)
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	cases := []struct {
+		name  string
+		files []templateFile
+		want  string
+	}{
+		{"empty", []templateFile{{Name: "a.tmpl", Content: " "}}, "a.tmpl: template content is empty"},
+		{"duplicate type", []templateFile{{Name: "a/x.tmpl", Content: "x"}, {Name: "b/x.tmpl", Content: "y"}}, "both generate type X"},
+		{"mixed specs", []templateFile{{Name: "a.tmpl", Content: "{{a:%d}} {{a}}"}}, `placeholder "a" is used with different format specs`},
+		{"list and value", []templateFile{{Name: "a.tmpl", Content: "{{a,}} {{a}}"}}, `placeholder "a" is used with different format specs`},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			_, err := generate("p", c.files, "{{", "}}", easytmpl.WithFormatSpecs(), easytmpl.WithListSpecs())
+			if err == nil || !strings.Contains(err.Error(), c.want) {
+				t.Errorf("got %v  want:%v", err, c.want)
+			}
+		})
+	}
+}
+
+func TestGenerate_withoutSpecs(t *testing.T) {
+	src, err := generate("p", []templateFile{{Name: "a.tmpl", Content: "{{host:port}} {{a,b}}"}}, "{{", "}}")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	for _, want := range []string{"HostPort string", "AB string"} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("got\n%s\nwant: %v", src, want)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	for s, want := range map[string]string{"user_name": "UserName", " name ": "Name", "order-id": "OrderId", "0": "Arg0", "": "Arg"} {
+		if got := identifier(s, "Arg"); got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+	}
+}
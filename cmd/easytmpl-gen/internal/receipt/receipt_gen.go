@@ -0,0 +1,58 @@
+// Code generated by easytmpl-gen. DO NOT EDIT.
+
+package receipt
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Receipt renders receipt.tmpl.
+type Receipt struct {
+	// Id is the value of the placeholder "id".
+	Id int64
+	// Name is the value of the placeholder "name".
+	Name string
+	// Total is the value of the placeholder "total".
+	Total float64
+	// Paid is the value of the placeholder "paid".
+	Paid bool
+	// Code is the value of the placeholder "code".
+	Code int64
+	// Note is the value of the placeholder "note".
+	Note string
+	// Tags is the value of the placeholder "tags".
+	Tags []string
+	// Q is the value of the placeholder "q".
+	Q string
+}
+
+// Render writes the template to w.
+func (t *Receipt) Render(w io.Writer) error {
+	for _, s := range [...]string{
+		"Receipt ",
+		fmt.Sprintf("%05d", t.Id),
+		" for ",
+		t.Name,
+		": ",
+		fmt.Sprintf("%.2f", t.Total),
+		" EUR, paid ",
+		fmt.Sprintf("%t", t.Paid),
+		", code ",
+		fmt.Sprintf("%x", t.Code),
+		".\nNote: ",
+		fmt.Sprintf("%q", t.Note),
+		", tags ",
+		strings.Join(t.Tags, "|"),
+		", link /r?",
+		url.Values{"q": {t.Q}}.Encode(),
+		"\n",
+	} {
+		if _, err := io.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package receipt
+
+import (
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+//go:embed receipt.tmpl
+var source string
+
+// TestReceipt_Render checks that the generated renderer writes what ExecString renders for the same values.
+func TestReceipt_Render(t *testing.T) {
+	template, err := easytmpl.NewTemplate(source, easytmpl.WithFormatSpecs(), easytmpl.WithListSpecs())
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	want, err := template.ExecString(map[string]string{
+		"id": "42", "name": "Ada", "total": "9.5", "paid": "true", "code": "255",
+		"note": `say "hi"`, "tags": "a|b", "q": "x y",
+	}, true)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	r := &Receipt{Id: 42, Name: "Ada", Total: 9.5, Paid: true, Code: 255, Note: `say "hi"`, Tags: []string{"a", "b"}, Q: "x y"}
+	var sb strings.Builder
+	if err := r.Render(&sb); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if got := sb.String(); got != want {
+		t.Errorf("got %q  want:%q", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+// Command easytmpl-gen generates type-safe renderers for easytmpl templates.
+//
+// For each template file it emits a struct with one string field per placeholder and a
+// Render(w io.Writer) error method that writes the static content and the fields directly, so that
+// renaming a placeholder breaks the build of the callers instead of their output.
+//
+// Usage:
+//
+//	easytmpl-gen [-o output.go] [-pkg name] [-start {{] [-end }}] [-specs] [-lists] file...
+//
+// It is meant to be run by go generate:
+//
+//	//go:generate easytmpl-gen -o templates_gen.go welcome.tmpl receipt.tmpl
+//
+// The type of each template is named after its file, e.g. WelcomeEmail for welcome_email.tmpl.
+// With -specs, the templates are parsed with easytmpl.WithFormatSpecs: placeholders with a fmt verb spec get
+// a field of the type the verb formats, e.g. float64 for `{{price:%.2f}}`, int64 for `{{n:%d}}`, bool for
+// `{{ok:%t}}` and string for `{{name:%q}}`, and placeholders with a time layout spec, e.g. `{{day:2006-01-02}}`,
+// get a field of type time.Time.
+// With -lists, the templates are parsed with easytmpl.WithListSpecs: list placeholders, e.g. `{{tags,}}`, get a
+// field of type []string, and query placeholders, e.g. `{{?q}}`, render `q=` followed by the escaped value.
+// Only templates in the default TagSyntax are supported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+func main() {
+	var (
+		output = flag.String("o", "easytmpl_gen.go", "output file")
+		pkg    = flag.String("pkg", os.Getenv("GOPACKAGE"), "package of the generated file (default $GOPACKAGE)")
+		start  = flag.String("start", "{{", "start tag of placeholders")
+		end    = flag.String("end", "}}", "end tag of placeholders")
+		specs  = flag.Bool("specs", false, "parse format specs such as {{price:%.2f}}")
+		lists  = flag.Bool("lists", false, "parse list specs such as {{tags,}} and {{?q}}")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: easytmpl-gen [flags] file...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	files := make([]templateFile, 0, flag.NArg())
+	for _, name := range flag.Args() {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			fatal(err)
+		}
+		files = append(files, templateFile{Name: name, Content: string(b)})
+	}
+	var opts []easytmpl.OptionHandler
+	if *specs {
+		opts = append(opts, easytmpl.WithFormatSpecs())
+	}
+	if *lists {
+		opts = append(opts, easytmpl.WithListSpecs())
+	}
+	src, err := generate(*pkg, files, *start, *end, opts...)
+	if err != nil {
+		fatal(err)
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "easytmpl-gen:", err)
+	os.Exit(1)
+}
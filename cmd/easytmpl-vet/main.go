@@ -0,0 +1,18 @@
+// Command easytmpl-vet checks that literal easytmpl templates are rendered with the keys they use.
+// See package tmplcheck for the checks it runs.
+//
+// It can be run on its own or by go vet:
+//
+//	go install github.com/tylitianrui/easytmpl/cmd/easytmpl-vet@latest
+//	go vet -vettool=$(which easytmpl-vet) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/tylitianrui/easytmpl/tmplcheck"
+)
+
+func main() {
+	singlechecker.Main(tmplcheck.Analyzer)
+}
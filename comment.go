@@ -0,0 +1,212 @@
+package easytmpl
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+var (
+	// BlockUnclosedError indicates that a `{{block "name"}}` or `{{define "name"}}` tag is not closed
+	// with `{{/block}}` or `{{/define}}`.
+	BlockUnclosedError = errors.New("block is not closed")
+
+	// BlockUnexpectedCloseError indicates a `{{/block}}` or `{{/define}}` tag without a matching opening tag.
+	BlockUnexpectedCloseError = errors.New("unexpected end of block")
+
+	// BlockNameInvalidError indicates that the name of a block or define tag is not a quoted string.
+	BlockNameInvalidError = errors.New("invalid block name")
+)
+
+// Tag names of the directives, between the tags of the tag pair, e.g. `{{raw}}` and `{{/raw}}`.
+const (
+	rawOpen     = "raw"
+	rawClose    = "/raw"
+	blockOpen   = "block "
+	blockClose  = "/block"
+	defineOpen  = "define "
+	defineClose = "/define"
+)
+
+// sourceBlock is a named region of the template source: a block of a base template or a define of a child template.
+type sourceBlock struct {
+	name string
+	// tag is the offset of the opening tag; body is the range of the content between the tags.
+	tag  int
+	body [2]int
+}
+
+// directives is the result of scanning a template source for comments, raw blocks, blocks and defines.
+type directives struct {
+	// content is the source without comments, define sections and the tags of raw blocks and blocks;
+	// it is nil if the source has none of them.
+	content []byte
+	// raws are the ranges of content holding the inner content of raw blocks.
+	raws [][2]int
+	// offsets holds, for each run of the source copied to content, its offset in content and in the source.
+	offsets [][2]int
+	// blocks and defines are ranges of the source.
+	blocks  []sourceBlock
+	defines []sourceBlock
+}
+
+// scanDirectives scans the source src of t. Comments, e.g. `{{! note for translators }}`, cannot be nested and
+// end at the first end tag. The content of raw blocks, e.g. `{{raw}}…{{/raw}}`, is not scanned; a `{{raw}}` tag
+// without a matching `{{/raw}}` is left as a placeholder with the key raw, as before raw blocks existed. Blocks,
+// e.g. `{{block "body"}}default{{/block}}`, can be nested; defines, e.g. `{{define "body"}}…{{/define}}`, cannot.
+func (t *Template) scanDirectives(src []byte) (*directives, error) {
+	var (
+		start, end = t.pairs.start, t.pairs.end
+		tag        = func(name string) []byte { return append(append(append([]byte(nil), start...), name...), end...) }
+		rawTag     = tag(rawOpen)
+		rawEnd     = tag(rawClose)
+		blockEnd   = tag(blockClose)
+		defineEnd  = tag(defineClose)
+		d          = &directives{}
+		stack      []sourceBlock
+		out        []byte
+		last       = 0
+		found      = false
+	)
+	// copySrc copies the source range [from, to) to out.
+	copySrc := func(from, to int) {
+		if from < to {
+			d.offsets = append(d.offsets, [2]int{len(out), from})
+			out = append(out, src[from:to]...)
+		}
+	}
+	// name returns the quoted name of the directive tag starting at k, whose name starts at from, and the end of the tag.
+	name := func(k, from int) (string, int, error) {
+		e := bytes.Index(src[from:], end)
+		if e < 0 {
+			return "", 0, t.diagnostic(BlockUnclosedError, k, "close the tag with "+strconv.Quote(string(end)))
+		}
+		quoted := bytes.TrimSpace(src[from : from+e])
+		s, err := strconv.Unquote(string(quoted))
+		if err != nil || quoted[0] == '\'' {
+			return "", 0, t.diagnostic(BlockNameInvalidError, k, `quote the name, e.g. {{block "body"}}`)
+		}
+		return s, from + e + len(end), nil
+	}
+
+	for p := 0; ; {
+		k := bytes.Index(src[p:], start)
+		if k < 0 {
+			break
+		}
+		k += p
+		inner := k + len(start)
+		next := 0
+
+		switch {
+		case inner < len(src) && src[inner] == '!':
+			e := bytes.Index(src[inner+1:], end)
+			if e < 0 {
+				p = inner
+				continue
+			}
+			copySrc(last, k)
+			next = inner + 1 + e + len(end)
+		case bytes.HasPrefix(src[k:], rawTag):
+			body := k + len(rawTag)
+			c := bytes.Index(src[body:], rawEnd)
+			if c < 0 {
+				// not a raw block: a placeholder whose key is raw.
+				p = inner
+				continue
+			}
+			copySrc(last, k)
+			d.raws = append(d.raws, [2]int{len(out), len(out) + c})
+			copySrc(body, body+c)
+			next = body + c + len(rawEnd)
+		case bytes.HasPrefix(src[inner:], []byte(blockOpen)):
+			s, body, err := name(k, inner+len(blockOpen))
+			if err != nil {
+				return nil, err
+			}
+			copySrc(last, k)
+			stack = append(stack, sourceBlock{name: s, tag: k, body: [2]int{body, 0}})
+			next = body
+		case bytes.HasPrefix(src[k:], blockEnd):
+			if len(stack) == 0 {
+				return nil, t.diagnostic(BlockUnexpectedCloseError, k, "no block is open")
+			}
+			b := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			b.body[1] = k
+			d.blocks = append(d.blocks, b)
+			copySrc(last, k)
+			next = k + len(blockEnd)
+		case bytes.HasPrefix(src[inner:], []byte(defineOpen)):
+			s, body, err := name(k, inner+len(defineOpen))
+			if err != nil {
+				return nil, err
+			}
+			c := bytes.Index(src[body:], defineEnd)
+			if c < 0 {
+				return nil, t.diagnostic(BlockUnclosedError, k, "close the define with "+string(defineEnd))
+			}
+			d.defines = append(d.defines, sourceBlock{name: s, tag: k, body: [2]int{body, body + c}})
+			copySrc(last, k)
+			next = body + c + len(defineEnd)
+		case bytes.HasPrefix(src[k:], defineEnd):
+			return nil, t.diagnostic(BlockUnexpectedCloseError, k, "no define is open")
+		default:
+			p = inner
+			continue
+		}
+		found, last, p = true, next, next
+	}
+	if len(stack) > 0 {
+		b := stack[len(stack)-1]
+		return nil, t.diagnostic(BlockUnclosedError, b.tag, "close the block with "+string(blockEnd))
+	}
+	if found {
+		copySrc(last, len(src))
+		// a source holding only directives has an empty, non-nil content.
+		d.content = append(make([]byte, 0, len(out)), out...)
+	}
+	return d, nil
+}
+
+// parseComments drops comments, define sections and the tags of raw blocks and blocks from the content,
+// keeping the original content in t.source. It returns the ranges of the content holding the inner
+// content of raw blocks, which must not be scanned for placeholders.
+func (t *Template) parseComments() ([][2]int, error) {
+	d, err := t.scanDirectives(t.content)
+	if err != nil || d.content == nil {
+		return nil, err
+	}
+	t.source = t.content
+	t.content = d.content
+	t.sourceOffsets = d.offsets
+	return d.raws, nil
+}
+
+// sourceOffset converts an offset in the content to an offset in the source as written. With end set,
+// offset is the end of a range, which is mapped to the end of the run of content it closes rather than
+// the start of the next run.
+func (t *Template) sourceOffset(offset int, end bool) int {
+	if t.source == nil || offset < 0 {
+		return offset
+	}
+	i := sort.Search(len(t.sourceOffsets), func(i int) bool {
+		if end {
+			return t.sourceOffsets[i][0] >= offset
+		}
+		return t.sourceOffsets[i][0] > offset
+	}) - 1
+	if i < 0 {
+		return offset
+	}
+	return t.sourceOffsets[i][1] + offset - t.sourceOffsets[i][0]
+}
+
+// sourceText returns the template as written, including its comments and raw blocks.
+func (t *Template) sourceText() []byte {
+	if t.source != nil {
+		return t.source
+	}
+	return t.content
+}
@@ -0,0 +1,120 @@
+package easytmpl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_Comments(t *testing.T) {
+	cases := []struct {
+		name string
+		tpl  string
+		opts []OptionHandler
+		args map[string]string
+		keys map[string]int
+		want string
+	}{
+		{"comment", "Hello {{! the user's first name }}{{name}}!", nil, map[string]string{"name": "Ada"}, map[string]int{"name": 1}, "Hello Ada!"},
+		{"comment only", "{{! nothing to see }}", nil, nil, map[string]int{}, ""},
+		{"comment with tags", "a{{! {{b}} }}c{{d}}", nil, map[string]string{"d": "D"}, map[string]int{"d": 1}, "a }}cD"},
+		{"unclosed comment", "a {{! b", nil, nil, map[string]int{}, "a {{! b"},
+		{"raw", "Use {{raw}}{{name}}{{/raw}} for {{name}}.", nil, map[string]string{"name": "Ada"}, map[string]int{"name": 1}, "Use {{name}} for Ada."},
+		{"raw at end", "{{a}}{{raw}}{{b}}{{/raw}}", nil, map[string]string{"a": "A"}, map[string]int{"a": 1}, "A{{b}}"},
+		{"raw keeps comments", "{{raw}}{{! x }}{{/raw}}{{! y }}", nil, nil, map[string]int{}, "{{! x }}"},
+		{"raw pairs", "[[raw]]<% not %>[[not]][[/raw]] [[! c ]][[a]]", []OptionHandler{WithTagPair("[[", "]]")}, map[string]string{"a": "A"}, map[string]int{"a": 1}, "<% not %>[[not]] A"},
+		{"raw between placeholders", "{{a}}-{{raw}}{{{{/raw}}-{{b}}", nil, map[string]string{"a": "A", "b": "B"}, map[string]int{"a": 1, "b": 1}, "A-{{-B"},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := NewTemplate(c.tpl, c.opts...)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got := template.Placeholder(); !reflect.DeepEqual(got, c.keys) {
+				t.Errorf("got %v  want:%v", got, c.keys)
+			}
+			got, err := template.ExecString(c.args, true)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q  want:%q", got, c.want)
+			}
+			if template.String() != c.tpl {
+				t.Errorf("got %q  want:%q", template.String(), c.tpl)
+			}
+
+			data, err := template.MarshalBinary()
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			var decoded Template
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got, _ := decoded.ExecString(c.args, true); got != c.want || decoded.String() != c.tpl {
+				t.Errorf("got %q %q  want:%q %q", got, decoded.String(), c.want, c.tpl)
+			}
+		})
+	}
+
+	t.Run("case:raw placeholder", func(t *testing.T) {
+		template, err := NewTemplate("a\n{{raw}}{{b}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, _ := template.ExecString(map[string]string{"raw": "R", "b": "B"}, true); got != "a\nRB" {
+			t.Errorf("got %q  want:%q", got, "a\nRB")
+		}
+	})
+
+	t.Run("case:source positions", func(t *testing.T) {
+		template, err := NewTemplate("{{! translators:\n keep it short }}\nDear {{name}},{{raw}}{{x}}{{/raw}} {{b}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecString(nil, true)
+		var d *Diagnostic
+		if !errors.As(err, &d) {
+			t.Fatalf("got %v  want a Diagnostic", err)
+		}
+		if line, column := d.Position(); line != 3 || column != 6 {
+			t.Errorf("got %v:%v  want:3:6", line, column)
+		}
+		_, spans, _ := template.ExecWithSourceMap(map[string]string{"name": "Ada"}, false)
+		src := template.String()
+		for _, span := range spans {
+			if span.Kind == PlaceholderSpan && src[span.SourceStart:span.SourceEnd] != "{{"+span.Key+"}}" {
+				t.Errorf("got %q  want:%q", src[span.SourceStart:span.SourceEnd], "{{"+span.Key+"}}")
+			}
+		}
+		if line, column := template.LineColumn(spans[1].SourceStart); line != 3 || column != 6 {
+			t.Errorf("got %v:%v  want:3:6", line, column)
+		}
+		if got := src[spans[2].SourceStart:spans[2].SourceEnd]; got != ",{{raw}}{{x}}{{/raw}} " {
+			t.Errorf("got %q  want:%q", got, ",{{raw}}{{x}}{{/raw}} ")
+		}
+
+		data, _ := template.MarshalBinary()
+		var decoded Template
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = decoded.ExecString(nil, true)
+		if !errors.As(err, &d) {
+			t.Fatalf("got %v  want a Diagnostic", err)
+		}
+		if line, column := d.Position(); line != 3 || column != 6 {
+			t.Errorf("got %v:%v  want:3:6", line, column)
+		}
+	})
+
+	t.Run("case:checksum of source", func(t *testing.T) {
+		a, _ := NewTemplate("x{{! one }}")
+		b, _ := NewTemplate("x{{! two }}")
+		if a.Checksum() == b.Checksum() {
+			t.Errorf("got equal checksums")
+		}
+	})
+}
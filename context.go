@@ -0,0 +1,54 @@
+package easytmpl
+
+import (
+	"context"
+	"io"
+	"strconv"
+)
+
+// ContextResolver resolves placeholders during ExecContext. It writes the value of key to w and
+// should abandon slow lookups when ctx is done.
+type ContextResolver func(ctx context.Context, w io.Writer, key string) (int, error)
+
+// ContextError is returned by ExecContext when the context is canceled or its deadline is exceeded during rendering.
+// It unwraps to the context's error, so errors.Is(err, context.Canceled) and
+// errors.Is(err, context.DeadlineExceeded) work as expected.
+type ContextError struct {
+	// Index is the index of the placeholder that was reached when rendering stopped.
+	Index int
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ContextError) Error() string {
+	return "render stopped at placeholder " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the context's error.
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// ExecContext renders the template to w, calling resolver with ctx for each placeholder.
+// The context is checked before each placeholder is resolved; once it is done, rendering stops and
+// a ContextError wrapping ctx.Err() is returned. An error returned by the resolver after the context is
+// done is reported the same way; other resolver errors are returned as is.
+// It returns TemplateModeError for templates in Mustache mode or in ShellSyntax, as ExecuteFunc does.
+func (t *Template) ExecContext(ctx context.Context, w io.Writer, resolver ContextResolver) (err error) {
+	defer t.wrapError(&err)
+	if t.mustache || t.syntax == ShellSyntax {
+		return TemplateModeError
+	}
+	return t.exec(w, func(w io.Writer, i int) error {
+		if err := ctx.Err(); err != nil {
+			return &ContextError{Index: i, Err: err}
+		}
+		if _, err := resolver(ctx, w, b2s(t.args[i])); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return &ContextError{Index: i, Err: ctxErr}
+			}
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,71 @@
+package easytmpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTemplate_ExecContext(t *testing.T) {
+	template, err := NewTemplate("{{a}}-{{b}}-{{c}}")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	t.Run("case:render", func(t *testing.T) {
+		var bb bytes.Buffer
+		err := template.ExecContext(context.Background(), &bb, func(ctx context.Context, w io.Writer, key string) (int, error) {
+			return w.Write([]byte(key + key))
+		})
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "aa-bb-cc"; bb.String() != want {
+			t.Errorf("got %q  want:%q", bb.String(), want)
+		}
+	})
+
+	t.Run("case:canceled between placeholders", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var bb bytes.Buffer
+		err := template.ExecContext(ctx, &bb, func(ctx context.Context, w io.Writer, key string) (int, error) {
+			if key == "b" {
+				cancel()
+			}
+			return w.Write([]byte(key))
+		})
+		var ce *ContextError
+		if !errors.As(err, &ce) || ce.Index != 2 || !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v", err)
+		}
+		if want := "a-b-"; bb.String() != want {
+			t.Errorf("got %q  want:%q", bb.String(), want)
+		}
+	})
+
+	t.Run("case:deadline exceeded in resolver", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		err := template.ExecContext(ctx, io.Discard, func(ctx context.Context, w io.Writer, key string) (int, error) {
+			<-ctx.Done()
+			return 0, errors.New("backend timeout")
+		})
+		var ce *ContextError
+		if !errors.As(err, &ce) || ce.Index != 0 || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got %v", err)
+		}
+	})
+
+	t.Run("case:resolver error", func(t *testing.T) {
+		want := errors.New("not found")
+		err := template.ExecContext(context.Background(), io.Discard, func(ctx context.Context, w io.Writer, key string) (int, error) {
+			return 0, want
+		})
+		if err != want {
+			t.Errorf("got %v  want:%v", err, want)
+		}
+	})
+}
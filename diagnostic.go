@@ -0,0 +1,139 @@
+package easytmpl
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is the error returned when a problem can be located in a template or a tag, e.g. an unclosed
+// Mustache section or a missing parameter in strict mode. It unwraps to the sentinel error describing the
+// problem, so errors.Is(err, TemplateExecMissingParameterError) keeps working.
+//
+// Its Error method returns the message of the sentinel error; Snippet, or formatting with `%+v`, returns
+// a multi-line report showing the offending line of the source with a caret under the column, and a hint.
+type Diagnostic struct {
+	Err error
+
+	// Source is the template content or the tag the problem was found in.
+	// Secret values bound with Bind are replaced by Redacted.
+	Source string
+
+	// Offset is the byte offset of the problem in Source; it is -1 if the problem concerns the whole source.
+	Offset int
+
+	// Hint suggests how to fix the problem; it may be empty.
+	Hint string
+}
+
+// newDiagnostic returns a Diagnostic for err at offset in source.
+func newDiagnostic(err error, source string, offset int, hint string) *Diagnostic {
+	return &Diagnostic{Err: err, Source: source, Offset: offset, Hint: hint}
+}
+
+// diagnostic returns a Diagnostic for err at offset in the template content.
+// The source is redacted as String does and offset is moved accordingly.
+func (t *Template) diagnostic(err error, offset int, hint string) *Diagnostic {
+	shift := 0
+	for _, r := range t.redactions {
+		if offset < r[0] {
+			break
+		}
+		if offset < r[1] {
+			offset = r[0]
+			break
+		}
+		shift += len(Redacted) - (r[1] - r[0])
+	}
+	if offset >= 0 {
+		offset += shift
+	}
+	if t.source != nil {
+		// offsets refer to the content without comments and raw block tags.
+		return newDiagnostic(err, string(t.source), t.sourceOffset(offset, false), hint)
+	}
+	return newDiagnostic(err, t.String(), offset, hint)
+}
+
+// missingParameter returns the Diagnostic for the missing value of the placeholder at index i.
+func (t *Template) missingParameter(i int) *Diagnostic {
+	return t.diagnostic(TemplateExecMissingParameterError, t.contentIntervalIdx[i][1],
+		"no value for "+strconv.Quote(b2s(t.args[i])))
+}
+
+// Error implements the error interface.
+func (d *Diagnostic) Error() string {
+	return d.Err.Error()
+}
+
+// Unwrap returns the sentinel error.
+func (d *Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// Position returns the 1-based line and column (in bytes) of the problem in Source, or 0, 0 if
+// the problem concerns the whole source.
+func (d *Diagnostic) Position() (line, column int) {
+	if d.Offset < 0 {
+		return 0, 0
+	}
+	offset := min(d.Offset, len(d.Source))
+	line = 1 + strings.Count(d.Source[:offset], "\n")
+	column = offset - strings.LastIndexByte(d.Source[:offset], '\n')
+	return line, column
+}
+
+// Snippet returns the multi-line report of the problem, e.g.
+//
+//	missing parameter
+//	 --> 2:6
+//	  |
+//	2 | Dear {{name}},
+//	  |      ^
+//	  = hint: no value for "name"
+func (d *Diagnostic) Snippet() string {
+	var sb strings.Builder
+	sb.WriteString(d.Err.Error())
+	line, column := d.Position()
+	gutter := strings.Repeat(" ", len(strconv.Itoa(line)))
+
+	if line > 0 {
+		offset := min(d.Offset, len(d.Source))
+		start := offset - column + 1
+		end := strings.IndexByte(d.Source[start:], '\n')
+		if end < 0 {
+			end = len(d.Source)
+		} else {
+			end += start
+		}
+		text := strings.TrimSuffix(d.Source[start:end], "\r")
+
+		fmt.Fprintf(&sb, "\n%s--> %d:%d\n%s |\n%d | %s\n%s | ", gutter, line, column, gutter, line, text, gutter)
+		// keep the caret aligned under tabs and multi-byte characters.
+		for _, r := range d.Source[start:offset] {
+			if r == '\t' {
+				sb.WriteByte('\t')
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteByte('^')
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(&sb, "\n%s = hint: %s", gutter, d.Hint)
+	}
+	return sb.String()
+}
+
+// Format implements fmt.Formatter: `%+v` prints Snippet, other verbs print Error.
+func (d *Diagnostic) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		io.WriteString(f, d.Snippet())
+	case verb == 'q':
+		io.WriteString(f, strconv.Quote(d.Error()))
+	default:
+		io.WriteString(f, d.Error())
+	}
+}
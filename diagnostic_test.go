@@ -0,0 +1,131 @@
+package easytmpl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostic(t *testing.T) {
+	t.Run("case:missing parameter", func(t *testing.T) {
+		template, err := NewTemplate("Hello,\nDear {{name}},")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecString(nil, true)
+		if !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Fatalf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+		var d *Diagnostic
+		if !errors.As(err, &d) {
+			t.Fatalf("got %v  want a Diagnostic", err)
+		}
+		if line, column := d.Position(); line != 2 || column != 6 {
+			t.Errorf("got %v:%v  want:2:6", line, column)
+		}
+		want := strings.Join([]string{
+			"missing parameter",
+			" --> 2:6",
+			"  |",
+			"2 | Dear {{name}},",
+			"  |      ^",
+			`  = hint: no value for "name"`,
+		}, "\n")
+		if got := d.Snippet(); got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+		if got := fmt.Sprintf("%+v", err); got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+		if got := fmt.Sprintf("%v", err); got != "missing parameter" {
+			t.Errorf("got %v  want:%v", got, "missing parameter")
+		}
+	})
+
+	t.Run("case:caret under tab", func(t *testing.T) {
+		template, err := NewTemplate("\t{{a}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecValues(nil, true)
+		var d *Diagnostic
+		if !errors.As(err, &d) {
+			t.Fatalf("got %v  want a Diagnostic", err)
+		}
+		if got := d.Snippet(); !strings.Contains(got, "\n  | \t^\n") {
+			t.Errorf("got %q  want a caret after the tab", got)
+		}
+	})
+
+	t.Run("case:empty content", func(t *testing.T) {
+		_, err := NewTemplate("   ")
+		if !errors.Is(err, TemplateContentEmptyError) {
+			t.Fatalf("got %v  want:%v", err, TemplateContentEmptyError)
+		}
+		var d *Diagnostic
+		if !errors.As(err, &d) || d.Offset != -1 {
+			t.Fatalf("got %#v  want a Diagnostic without offset", err)
+		}
+		if got, want := d.Snippet(), "template content is empty\n  = hint: the template must contain at least one non-blank character"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:tag contains space", func(t *testing.T) {
+		_, err := NewTagPair("<%", "% >")
+		if !errors.Is(err, TagContainSpaceError) {
+			t.Fatalf("got %v  want:%v", err, TagContainSpaceError)
+		}
+		var d *Diagnostic
+		if !errors.As(err, &d) || d.Source != "% >" || d.Offset != 1 {
+			t.Fatalf("got %#v  want a Diagnostic at offset 1", err)
+		}
+		if _, err := NewTemplate("{{a}}", WithTagPair("", "}}")); !errors.Is(err, TagEmptyError) {
+			t.Errorf("got %v  want:%v", err, TagEmptyError)
+		}
+	})
+
+	t.Run("case:mustache", func(t *testing.T) {
+		_, err := NewTemplate("a\n{{#items}}\n{{name}}\n", WithMustache())
+		var d *Diagnostic
+		if !errors.Is(err, MustacheUnclosedSectionError) || !errors.As(err, &d) {
+			t.Fatalf("got %v  want:%v", err, MustacheUnclosedSectionError)
+		}
+		if line, column := d.Position(); line != 2 || column != 1 {
+			t.Errorf("got %v:%v  want:2:1", line, column)
+		}
+
+		_, err = NewTemplate("{{#a}}{{/b}}", WithMustache())
+		if !errors.Is(err, MustacheUnexpectedCloseError) || !errors.As(err, &d) || d.Offset != 6 {
+			t.Errorf("got %v  want:%v at offset 6", err, MustacheUnexpectedCloseError)
+		}
+
+		template, err := NewTemplate("x {{name}}", WithMustache())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecString(nil, true)
+		if !errors.Is(err, TemplateExecMissingParameterError) || !errors.As(err, &d) || d.Offset != 2 {
+			t.Errorf("got %v  want:%v at offset 2", err, TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:redacted source", func(t *testing.T) {
+		template, err := NewTemplate("{{secret.token}} {{user}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.Bind(map[string]string{"secret.token": "s3cr3t-value"}).ExecString(nil, true)
+		var d *Diagnostic
+		if !errors.As(err, &d) {
+			t.Fatalf("got %v  want a Diagnostic", err)
+		}
+		if strings.Contains(d.Snippet(), "s3cr3t") {
+			t.Errorf("got %q  want the secret redacted", d.Snippet())
+		}
+		if got, want := d.Source[d.Offset:], "{{user}}"; got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+	})
+}
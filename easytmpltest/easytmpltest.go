@@ -0,0 +1,191 @@
+// Package easytmpltest provides helpers for testing easytmpl templates: render assertions with
+// failure messages pointing at the placeholder whose output differs, golden files and placeholder set assertions.
+//
+// Golden files are rewritten with the output of the template when the tests run with the -easytmpltest.update flag:
+//
+//	go test ./mail -easytmpltest.update
+//
+// The flag name is prefixed with the package name so that it does not clash with an -update flag of the test
+// package; tests can call Update to rewrite their own golden files with the same flag.
+package easytmpltest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+var update = flag.Bool("easytmpltest.update", false, "update the golden files of easytmpltest.AssertGolden")
+
+// Update reports whether the tests run with the -easytmpltest.update flag.
+func Update() bool {
+	return *update
+}
+
+// AssertRender renders tpl with args in strict mode and reports an error on t if rendering fails
+// or the output differs from want. The failure message shows the first differing line and tells which
+// placeholder, or which static content of the template, produced it.
+func AssertRender(t testing.TB, tpl *easytmpl.Template, args map[string]string, want string) {
+	t.Helper()
+	got, spans, err := render(tpl, args)
+	if err != nil {
+		t.Errorf("render failed: %v", err)
+		return
+	}
+	if got != want {
+		t.Errorf("%s", Diff(tpl, spans, got, want))
+	}
+}
+
+// AssertGolden renders tpl with args in strict mode and compares the output with the content of the golden
+// file at path. With the -easytmpltest.update flag, the golden file is written with the output instead.
+func AssertGolden(t testing.TB, tpl *easytmpl.Template, args map[string]string, path string) {
+	t.Helper()
+	got, spans, err := render(tpl, args)
+	if err != nil {
+		t.Errorf("render failed: %v", err)
+		return
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("update golden file: %v", err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("update golden file: %v", err)
+			return
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v (run the tests with -easytmpltest.update to create it)", err)
+		return
+	}
+	if got != string(want) {
+		t.Errorf("%s: %s", path, Diff(tpl, spans, got, string(want)))
+	}
+}
+
+// AssertPlaceholders reports an error on t if the set of placeholder keys of tpl is not keys.
+// The number of times each placeholder is used is ignored.
+func AssertPlaceholders(t testing.TB, tpl *easytmpl.Template, keys ...string) {
+	t.Helper()
+	got := make([]string, 0, len(tpl.Placeholder()))
+	for k := range tpl.Placeholder() {
+		got = append(got, k)
+	}
+	sort.Strings(got)
+	want := append([]string(nil), keys...)
+	sort.Strings(want)
+	want = compact(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("placeholders %q, want %q", got, want)
+	}
+}
+
+// AssertPlaceholderCounts reports an error on t if the placeholders of tpl and the number of times
+// they are used are not counts.
+func AssertPlaceholderCounts(t testing.TB, tpl *easytmpl.Template, counts map[string]int) {
+	t.Helper()
+	if got := tpl.Placeholder(); !reflect.DeepEqual(got, counts) {
+		t.Errorf("placeholders %v, want %v", got, counts)
+	}
+}
+
+// compact removes consecutive duplicates from the sorted keys.
+func compact(keys []string) []string {
+	out := keys[:0]
+	for i, k := range keys {
+		if i == 0 || k != keys[i-1] {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// render renders tpl with args in strict mode, with a source map unless tpl is in Mustache mode.
+func render(tpl *easytmpl.Template, args map[string]string) (string, easytmpl.SourceMap, error) {
+	got, spans, err := tpl.ExecWithSourceMap(args, true)
+	if errors.Is(err, easytmpl.TemplateModeError) {
+		got, err = tpl.ExecString(args, true)
+	}
+	return got, spans, err
+}
+
+// Diff describes the first difference between the output got of tpl and want, e.g.
+//
+//	output differs at line 2, column 6, in placeholder "name" (template line 2, column 6):
+//	  got:  Dear Bob,
+//	  want: Dear Ada,
+//	             ^
+//
+// spans is the source map of got; without it the message does not tell where the output comes from.
+// Lines holding the value of a secret placeholder are not shown.
+func Diff(tpl *easytmpl.Template, spans easytmpl.SourceMap, got, want string) string {
+	offset := 0
+	for offset < len(got) && offset < len(want) && got[offset] == want[offset] {
+		offset++
+	}
+	line := 1 + strings.Count(got[:offset], "\n")
+	lineStart := strings.LastIndexByte(got[:offset], '\n') + 1
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "output differs at line %d, column %d", line, offset-lineStart+1)
+
+	span, ok := spans.Lookup(offset)
+	if !ok && offset > 0 {
+		// the outputs differ in length: blame what produced the last byte.
+		span, ok = spans.Lookup(offset - 1)
+	}
+	if ok {
+		l, c := tpl.LineColumn(span.SourceStart)
+		if span.Kind == easytmpl.PlaceholderSpan {
+			fmt.Fprintf(&sb, ", in placeholder %q (template line %d, column %d)", span.Key, l, c)
+		} else {
+			fmt.Fprintf(&sb, ", in static content (template line %d, column %d)", l, c)
+		}
+	}
+	lineEnd := len(got)
+	if i := strings.IndexByte(got[lineStart:], '\n'); i >= 0 {
+		lineEnd = lineStart + i
+	}
+	for _, s := range spans {
+		if s.Secret && s.Start <= lineEnd && lineStart < s.End {
+			sb.WriteString(": line not shown, it holds a secret value")
+			return sb.String()
+		}
+	}
+	sb.WriteString(":\n")
+
+	// the lines of got and want containing offset; they start at the same offset since the prefixes are equal.
+	fmt.Fprintf(&sb, "  got:  %s\n", lineAt(got, lineStart))
+	fmt.Fprintf(&sb, "  want: %s\n", lineAt(want, lineStart))
+	sb.WriteString("        ")
+	for _, r := range got[lineStart:offset] {
+		if r == '\t' {
+			sb.WriteByte('\t')
+		} else {
+			sb.WriteByte(' ')
+		}
+	}
+	sb.WriteByte('^')
+	return sb.String()
+}
+
+// lineAt returns the line of s starting at offset start, marking the end of s.
+func lineAt(s string, start int) string {
+	s = s[start:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s + "<EOF>"
+}
@@ -0,0 +1,146 @@
+package easytmpltest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+// recorder records the failures reported by the assertions.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func newTemplate(t *testing.T, tpl string, opts ...easytmpl.OptionHandler) *easytmpl.Template {
+	t.Helper()
+	template, err := easytmpl.NewTemplate(tpl, opts...)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	return template
+}
+
+func TestAssertRender(t *testing.T) {
+	template := newTemplate(t, "Hello,\nDear {{name}}, you owe {{amount}}.")
+
+	t.Run("case:equal", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertRender(r, template, map[string]string{"name": "Ada", "amount": "3"}, "Hello,\nDear Ada, you owe 3.")
+		if len(r.errors) != 0 {
+			t.Errorf("got %v  want no error", r.errors)
+		}
+	})
+
+	t.Run("case:placeholder differs", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertRender(r, template, map[string]string{"name": "Bob", "amount": "3"}, "Hello,\nDear Ada, you owe 3.")
+		want := strings.Join([]string{
+			`output differs at line 2, column 6, in placeholder "name" (template line 2, column 6):`,
+			"  got:  Dear Bob, you owe 3.<EOF>",
+			"  want: Dear Ada, you owe 3.<EOF>",
+			"             ^",
+		}, "\n")
+		if len(r.errors) != 1 || r.errors[0] != want {
+			t.Errorf("got %q  want:%q", r.errors, want)
+		}
+	})
+
+	t.Run("case:static differs", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertRender(r, template, map[string]string{"name": "Ada", "amount": "3"}, "Hello,\nDear Ada, you owe 3!")
+		if len(r.errors) != 1 || !strings.Contains(r.errors[0], "in static content (template line 2, column 34)") {
+			t.Errorf("got %q", r.errors)
+		}
+	})
+
+	t.Run("case:missing parameter", func(t *testing.T) {
+		r := &recorder{TB: t}
+		AssertRender(r, template, map[string]string{"name": "Ada"}, "")
+		if len(r.errors) != 1 || !strings.Contains(r.errors[0], "missing parameter") {
+			t.Errorf("got %q", r.errors)
+		}
+	})
+
+	t.Run("case:secret", func(t *testing.T) {
+		r := &recorder{TB: t}
+		secret := newTemplate(t, "token={{secret.token}}")
+		AssertRender(r, secret, map[string]string{"secret.token": "s3cr3t"}, "token=other")
+		if len(r.errors) != 1 || strings.Contains(r.errors[0], "s3cr3t") || !strings.Contains(r.errors[0], "not shown") {
+			t.Errorf("got %q", r.errors)
+		}
+	})
+
+	t.Run("case:mustache", func(t *testing.T) {
+		r := &recorder{TB: t}
+		mustache := newTemplate(t, "{{#items}}{{.}},{{/items}}", easytmpl.WithMustache())
+		AssertRender(r, mustache, nil, "")
+		if len(r.errors) != 0 {
+			t.Errorf("got %v  want no error", r.errors)
+		}
+	})
+}
+
+func TestUpdateFlag(t *testing.T) {
+	// test packages importing easytmpltest may define their own -update flag.
+	if flag.Lookup("easytmpltest.update") == nil || flag.Lookup("update") != nil {
+		t.Errorf("got %v %v  want the easytmpltest.update flag only", flag.Lookup("easytmpltest.update"), flag.Lookup("update"))
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	template := newTemplate(t, "Dear {{name}},\n")
+	path := filepath.Join(t.TempDir(), "testdata", "letter.golden")
+
+	r := &recorder{TB: t}
+	AssertGolden(r, template, map[string]string{"name": "Ada"}, path)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-easytmpltest.update") {
+		t.Fatalf("got %q", r.errors)
+	}
+
+	*update = true
+	r = &recorder{TB: t}
+	AssertGolden(r, template, map[string]string{"name": "Ada"}, path)
+	*update = false
+	if b, err := os.ReadFile(path); err != nil || string(b) != "Dear Ada,\n" {
+		t.Fatalf("got %q %v  want:%q", b, err, "Dear Ada,\n")
+	}
+
+	AssertGolden(r, template, map[string]string{"name": "Ada"}, path)
+	AssertGolden(r, template, map[string]string{"name": "Bob"}, path)
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], `in placeholder "name"`) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestAssertPlaceholders(t *testing.T) {
+	template := newTemplate(t, "{{a}} {{b}} {{a}}")
+
+	r := &recorder{TB: t}
+	AssertPlaceholders(r, template, "b", "a")
+	AssertPlaceholderCounts(r, template, map[string]int{"a": 2, "b": 1})
+	if len(r.errors) != 0 {
+		t.Errorf("got %v  want no error", r.errors)
+	}
+
+	AssertPlaceholders(r, template, "a")
+	AssertPlaceholderCounts(r, template, map[string]int{"a": 1, "b": 1})
+	if len(r.errors) != 2 {
+		t.Errorf("got %v  want 2 errors", r.errors)
+	}
+}
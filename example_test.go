@@ -4,7 +4,7 @@ import (
 	"fmt"
 )
 
-func ExampleTemplate_ExecString_NonStrictMode() {
+func ExampleTemplate_ExecString_nonStrictMode() {
 	tpl := "https://{{demain}}.com?name={{name}}&age={{age}}&birth={{birth}}"
 
 	// Create a new template instance with default tag pair `{{ }}` and pre-allocated memory of 1024 bytes.
@@ -36,7 +36,7 @@ func ExampleTemplate_ExecString_NonStrictMode() {
 	// template: https://user.google.com?name=tyltr&age=18&birth={{birth}}
 }
 
-func ExampleTemplate_ExecString_NonStrictModeAndAutoFill() {
+func ExampleTemplate_ExecString_nonStrictModeAndAutoFill() {
 	tpl := "https://[[demain]].com?name=[[name]]&age=[[age]]&birth=[[birth]]"
 	t, err := NewTemplate(tpl,
 		WithTagPair("[[", "]]"),     // set custom tag pair `[[` & `]]`
@@ -67,7 +67,7 @@ func ExampleTemplate_ExecString_NonStrictModeAndAutoFill() {
 	// template: https://user.google.com?name=tyltr&age=18&birth=
 }
 
-func ExampleTemplate_ExecString_StrictMode() {
+func ExampleTemplate_ExecString_strictMode() {
 	tpl := "https://{{demain}}.com?name={{name}}&age={{age}}&birth={{birth}}"
 	t, err := NewTemplate(tpl,
 		WithTagPair("{{", "}}"),
@@ -96,3 +96,19 @@ func ExampleTemplate_ExecString_StrictMode() {
 	// err: missing parameter
 	// template:
 }
+
+func ExampleTemplate_ExecArgs() {
+	t, err := NewTemplate("{{0}} failed after {{1}} retries")
+	if err != nil {
+		panic(err)
+	}
+
+	// Positional values are indexed directly, no map is allocated.
+	s, err := t.ExecArgs("upload", "3")
+	fmt.Println("err:", err)
+	fmt.Println("template:", s)
+
+	// Output:
+	// err: <nil>
+	// template: upload failed after 3 retries
+}
@@ -0,0 +1,96 @@
+package easytmpl
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+var (
+	// BlockNotFoundError indicates that a child template defines a block that its base template does not have.
+	BlockNotFoundError = errors.New("block not found in the base template")
+
+	// ExtendTagPairError indicates that a child template and its base template use different tag pairs.
+	ExtendTagPairError = errors.New("child and base templates use different tag pairs")
+)
+
+// Extend returns the template obtained by rendering the base template base as a layout for t:
+// the content of each `{{block "name"}}…{{/block}}` of base is replaced by the content of the
+// `{{define "name"}}…{{/define}}` of t, and blocks that t does not define keep their default content.
+// The content of t outside its defines is ignored.
+//
+// The result is a single flattened template, parsed once with the options of base, the name and version of t
+// (when set) and the secret keys of both. It keeps the tags of the blocks, so it can itself be extended.
+// Extend returns an error if t defines a block that base does not have, if the templates use different tag pairs,
+// if either is in Mustache mode or in ShellSyntax, or if either holds secret values bound with Bind.
+func (t *Template) Extend(base *Template) (_ *Template, err error) {
+	defer t.wrapError(&err)
+	if t.mustache || base.mustache || t.syntax != TagSyntax || base.syntax != TagSyntax {
+		return nil, TemplateModeError
+	}
+	if len(t.redactions) > 0 || len(base.redactions) > 0 {
+		return nil, TemplateBoundSecretError
+	}
+	if !bytes.Equal(t.pairs.start, base.pairs.start) || !bytes.Equal(t.pairs.end, base.pairs.end) {
+		return nil, ExtendTagPairError
+	}
+
+	src, baseSrc := t.sourceText(), base.sourceText()
+	child, err := t.scanDirectives(src)
+	if err != nil {
+		return nil, err
+	}
+	layout, err := base.scanDirectives(baseSrc)
+	if err != nil {
+		return nil, err
+	}
+
+	defines := make(map[string][]byte, len(child.defines))
+	for _, d := range child.defines {
+		defines[d.name] = src[d.body[0]:d.body[1]]
+	}
+	blocks := layout.blocks
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].body[0] < blocks[j].body[0] })
+	defined := make(map[string]bool, len(defines))
+	for _, b := range blocks {
+		defined[b.name] = true
+	}
+	for _, d := range child.defines {
+		if !defined[d.name] {
+			return nil, newDiagnostic(BlockNotFoundError, string(src), d.tag, "the base template has no block "+strconv.Quote(d.name))
+		}
+	}
+
+	content := make([]byte, 0, len(baseSrc))
+	last := 0
+	for _, b := range blocks {
+		body, ok := defines[b.name]
+		// blocks nested in a replaced block are replaced with it.
+		if !ok || b.body[0] < last {
+			continue
+		}
+		content = append(content, baseSrc[last:b.body[0]]...)
+		content = append(content, body...)
+		last = b.body[1]
+	}
+	content = append(content, baseSrc[last:]...)
+
+	nt, err := base.recompile(content)
+	if err != nil {
+		return nil, err
+	}
+	if t.name != "" || t.version != "" {
+		nt.name, nt.version = t.name, t.version
+	}
+	if len(t.secrets) > 0 {
+		nt.secrets = make(map[string]struct{}, len(base.secrets)+len(t.secrets))
+		for k := range base.secrets {
+			nt.secrets[k] = struct{}{}
+		}
+		for k := range t.secrets {
+			nt.secrets[k] = struct{}{}
+		}
+	}
+	return nt, nil
+}
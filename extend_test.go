@@ -0,0 +1,94 @@
+package easytmpl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_Extend(t *testing.T) {
+	base, err := NewTemplate(`<title>{{block "title"}}Acme{{/block}}</title>
+<body>{{block "body"}}Hello {{name}}{{block "sign"}}, Acme{{/block}}{{/block}}</body>`, WithName("layout"), WithSecretKeys("token"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	t.Run("case:base alone", func(t *testing.T) {
+		got, err := base.ExecString(map[string]string{"name": "Ada"}, true)
+		if want := "<title>Acme</title>\n<body>Hello Ada, Acme</body>"; got != want || err != nil {
+			t.Errorf("got %q %v  want:%q", got, err, want)
+		}
+	})
+
+	t.Run("case:child", func(t *testing.T) {
+		child, err := NewTemplate(`ignored {{define "title"}}Receipt {{id}}{{/define}}
+{{define "sign"}}, the {{team}} team{{/define}}`, WithName("receipt"), WithSecretKeys("key"))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, _ := child.ExecString(nil, false); got != "ignored \n" {
+			t.Errorf("got %q  want:%q", got, "ignored \n")
+		}
+
+		page, err := child.Extend(base)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := page.ExecString(map[string]string{"name": "Ada", "id": "42", "team": "billing"}, true)
+		if want := "<title>Receipt 42</title>\n<body>Hello Ada, the billing team</body>"; got != want || err != nil {
+			t.Errorf("got %q %v  want:%q", got, err, want)
+		}
+		if want := map[string]int{"id": 1, "name": 1, "team": 1}; !reflect.DeepEqual(page.Placeholder(), want) {
+			t.Errorf("got %v  want:%v", page.Placeholder(), want)
+		}
+		if page.Name() != "receipt" || !page.IsSecret("token") || !page.IsSecret("key") {
+			t.Errorf("got %v %v %v", page.Name(), page.IsSecret("token"), page.IsSecret("key"))
+		}
+
+		// the result keeps its blocks and can be extended again.
+		grandchild, _ := NewTemplate(`{{define "title"}}Refund{{/define}}`)
+		refund, err := grandchild.Extend(page)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, _ = refund.ExecString(map[string]string{"name": "Ada", "team": "billing"}, true)
+		if want := "<title>Refund</title>\n<body>Hello Ada, the billing team</body>"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:nested block replaced", func(t *testing.T) {
+		child, _ := NewTemplate(`{{define "body"}}Bye{{/define}}{{define "sign"}}!{{/define}}`)
+		page, err := child.Extend(base)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, _ := page.ExecString(nil, true)
+		if want := "<title>Acme</title>\n<body>Bye</body>"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:errors", func(t *testing.T) {
+		child, _ := NewTemplate(`{{define "footer"}}x{{/define}}`)
+		if _, err := child.Extend(base); !errors.Is(err, BlockNotFoundError) {
+			t.Errorf("got %v  want:%v", err, BlockNotFoundError)
+		}
+		other, _ := NewTemplate(`[[define "title"]]x[[/define]]`, WithTagPair("[[", "]]"))
+		if _, err := other.Extend(base); !errors.Is(err, ExtendTagPairError) {
+			t.Errorf("got %v  want:%v", err, ExtendTagPairError)
+		}
+		for tpl, want := range map[string]error{
+			`{{block "a"}}x`:            BlockUnclosedError,
+			`{{define "a"}}x`:           BlockUnclosedError,
+			`x{{/block}}`:               BlockUnexpectedCloseError,
+			`x{{/define}}`:              BlockUnexpectedCloseError,
+			`{{block a}}x{{/block}}`:    BlockNameInvalidError,
+			`{{define 'a'}}{{/define}}`: BlockNameInvalidError,
+		} {
+			if _, err := NewTemplate(tpl); !errors.Is(err, want) {
+				t.Errorf("%s: got %v  want:%v", tpl, err, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package easytmpl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// valueFormat is the format spec of a placeholder, e.g. `%.2f` in `{{price:%.2f}}` or
+// `2006-01-02` in `{{ts:2006-01-02}}`. Specs starting with `%` are fmt verbs; any other spec
+// is a time layout applied to time.Time values.
+type valueFormat struct {
+	verb   string
+	layout string
+	// prec is the precision of a `%.Nf` verb, which is formatted without going through fmt; -1 otherwise.
+	prec int
+}
+
+// parseFormats splits each placeholder at its first `:` into the key and its format spec.
+// t.formats is left nil if no placeholder has a format spec.
+func (t *Template) parseFormats() {
+	for i, a := range t.args {
+		k := bytes.IndexByte(a, ':')
+		if k < 0 {
+			continue
+		}
+		if t.formats == nil {
+			t.formats = make([]valueFormat, len(t.args))
+		}
+		t.args[i] = a[:k]
+		t.formats[i] = newValueFormat(b2s(a[k+1:]))
+	}
+}
+
+// newValueFormat parses a format spec.
+func newValueFormat(spec string) valueFormat {
+	f := valueFormat{prec: -1}
+	if len(spec) == 0 || spec[0] != '%' {
+		f.layout = spec
+		return f
+	}
+	f.verb = spec
+	if len(spec) >= 4 && spec[1] == '.' && spec[len(spec)-1] == 'f' {
+		if p, err := strconv.Atoi(spec[2 : len(spec)-1]); err == nil && p >= 0 {
+			f.prec = p
+		}
+	}
+	return f
+}
+
+// format returns the format spec of the placeholder at index i.
+func (t *Template) format(i int) valueFormat {
+	if t.formats == nil {
+		return valueFormat{prec: -1}
+	}
+	return t.formats[i]
+}
+
+// placeholder returns the source text of the placeholder at index i, including its tags.
+func (t *Template) placeholder(i int) []byte {
+	return t.content[t.contentIntervalIdx[i][1]:t.contentIntervalIdx[i+1][0]]
+}
+
+// appendValue appends the formatted value v to dst.
+// Common types are formatted with strconv; other types, and fmt verbs other than `%.Nf`, go through fmt.
+// A string formatted with a numeric or boolean verb, e.g. "9.5" with `%.2f`, is parsed first and written as it is
+// if it does not parse; see parseValue.
+func appendValue(dst []byte, v any, f valueFormat) []byte {
+	if f.verb != "" {
+		if x, ok := v.(string); ok {
+			if v, ok = parseValue(x, f.verb[len(f.verb)-1]); !ok {
+				return append(dst, x...)
+			}
+		}
+		if f.prec >= 0 {
+			switch x := v.(type) {
+			case float64:
+				return strconv.AppendFloat(dst, x, 'f', f.prec, 64)
+			case float32:
+				return strconv.AppendFloat(dst, float64(x), 'f', f.prec, 32)
+			}
+		}
+		return fmt.Appendf(dst, f.verb, v)
+	}
+
+	switch x := v.(type) {
+	case nil:
+		return dst
+	case string:
+		return append(dst, x...)
+	case []byte:
+		return append(dst, x...)
+	case int:
+		return strconv.AppendInt(dst, int64(x), 10)
+	case int8:
+		return strconv.AppendInt(dst, int64(x), 10)
+	case int16:
+		return strconv.AppendInt(dst, int64(x), 10)
+	case int32:
+		return strconv.AppendInt(dst, int64(x), 10)
+	case int64:
+		return strconv.AppendInt(dst, x, 10)
+	case uint:
+		return strconv.AppendUint(dst, uint64(x), 10)
+	case uint8:
+		return strconv.AppendUint(dst, uint64(x), 10)
+	case uint16:
+		return strconv.AppendUint(dst, uint64(x), 10)
+	case uint32:
+		return strconv.AppendUint(dst, uint64(x), 10)
+	case uint64:
+		return strconv.AppendUint(dst, x, 10)
+	case float32:
+		return strconv.AppendFloat(dst, float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.AppendFloat(dst, x, 'g', -1, 64)
+	case bool:
+		return strconv.AppendBool(dst, x)
+	case time.Time:
+		if f.layout != "" {
+			return x.AppendFormat(dst, f.layout)
+		}
+		return x.AppendFormat(dst, time.RFC3339)
+	case time.Duration:
+		return append(dst, x.String()...)
+	case fmt.Stringer:
+		return append(dst, x.String()...)
+	case error:
+		return append(dst, x.Error()...)
+	}
+	return fmt.Append(dst, v)
+}
+
+// parseValue returns the string s as the type the fmt verb expects: an integer for `%d`, `%b`, `%o`, `%O`,
+// `%c` and `%U`, a float for `%e`, `%f` and `%g`, and a boolean for `%t`; `%x` converts decimal numbers and
+// applies to other strings. Other verbs, such as `%s`, `%q` and `%v`, apply to s itself. It returns false if s does not parse as the type the verb expects.
+func parseValue(s string, verb byte) (any, bool) {
+	var (
+		v   any
+		err error
+	)
+	switch verb {
+	case 'd', 'b', 'o', 'O', 'c', 'U':
+		v, err = strconv.ParseInt(s, 10, 64)
+	case 'x', 'X':
+		// hexadecimal applies to strings too: only decimal numbers are converted.
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, true
+		}
+		return s, true
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		v, err = strconv.ParseFloat(s, 64)
+	case 't':
+		v, err = strconv.ParseBool(s)
+	default:
+		return s, true
+	}
+	return v, err == nil
+}
+
+// ExecValues renders the template with typed arguments.
+// With WithFormatSpecs, values are formatted according to the placeholder's format spec, e.g. `{{price:%.2f}}`
+// or `{{ts:2006-01-02}}`; without a spec, numbers, booleans, times (RFC 3339), durations, fmt.Stringer and error values get their usual
+// string form. With WithListSpecs, the elements of a slice are joined by list placeholders such as `{{tags,}}`,
+// and a url.Values or a map is expanded into a query string by query placeholders such as `{{?params}}`;
+// see ExecLists.
+// strict and missing placeholders behave as in ExecString.
+func (t *Template) ExecValues(args map[string]any, strict bool) (_ string, err error) {
+	defer t.wrapError(&err)
+	var bb bytes.Buffer
+	bb.Grow(max(len(t.content)*2, t.capacity))
+
+	if t.mustache {
+		r := t.newMustacheRenderer(&bb, strict)
+		if err := r.render(t.nodes, []any{args}); err != nil {
+			return "", err
+		}
+		return bb.String(), nil
+	}
+	if t.syntax == ShellSyntax {
+		err := t.execShell(&bb, func(key string) (string, bool) {
+			v, ok := args[key]
+			return string(appendValue(nil, v, valueFormat{prec: -1})), ok
+		}, strict)
+		if err != nil {
+			return "", err
+		}
+		return bb.String(), nil
+	}
+
+	if strict {
+		for i, a := range t.args {
+			if _, ok := args[b2s(a)]; !ok {
+				t.missingKey(b2s(a))
+				return "", t.missingParameter(i)
+			}
+		}
+	}
+	// values are formatted into scratch rather than bb's available buffer, which the writer may write to first.
+	var scratch []byte
+	err = t.exec(&bb, func(w io.Writer, i int) error {
+		var err error
+		if v, ok := args[b2s(t.args[i])]; ok {
+			scratch = t.appendArg(scratch[:0], i, v)
+			_, err = w.Write(scratch)
+		} else if t.autoFill != nil {
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(*t.autoFill)
+		} else {
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(t.placeholder(i))
+		}
+		return err
+	})
+	return bb.String(), err
+}
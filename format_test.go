@@ -0,0 +1,109 @@
+package easytmpl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTemplate_parseFormats(t *testing.T) {
+	template, err := NewTemplate("{{price:%.2f}} {{ts:15:04:05}} {{name}}", WithFormatSpecs())
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := [][]byte{[]byte("price"), []byte("ts"), []byte("name")}; !reflect.DeepEqual(template.args, want) {
+		t.Errorf("got %q  want:%q", template.args, want)
+	}
+	want := []valueFormat{{verb: "%.2f", prec: 2}, {layout: "15:04:05", prec: -1}, {}}
+	if !reflect.DeepEqual(template.formats, want) {
+		t.Errorf("got %v  want:%v", template.formats, want)
+	}
+}
+
+func TestTemplate_ExecValues(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	args := map[string]any{
+		"name":  "tyltr",
+		"age":   18,
+		"price": 9.5,
+		"ok":    true,
+		"ts":    ts,
+		"wait":  1500 * time.Millisecond,
+		"nil":   nil,
+	}
+	tests := []struct {
+		tpl  string
+		want string
+	}{
+		{tpl: "{{name}} {{age}} {{price}} {{ok}} {{wait}} [{{nil}}]", want: "tyltr 18 9.5 true 1.5s []"},
+		{tpl: "{{price:%.2f}} {{age:%03d}} {{name:%q}}", want: `9.50 018 "tyltr"`},
+		{tpl: "{{ts}}", want: "2024-01-02T15:04:05Z"},
+		{tpl: "{{ts:2006-01-02}} {{ts:15:04}}", want: "2024-01-02 15:04"},
+		{tpl: "{{missing}} {{missing:%d}}", want: "{{missing}} {{missing:%d}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tpl, func(t *testing.T) {
+			template, err := NewTemplate(tt.tpl, WithFormatSpecs())
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			got, err := template.ExecValues(args, false)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q  want:%q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("case:strict mode", func(t *testing.T) {
+		template, err := NewTemplate("{{name}} {{missing:%d}}", WithFormatSpecs())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if _, err := template.ExecValues(args, true); !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Errorf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:without format specs", func(t *testing.T) {
+		template, err := NewTemplate("{{host:port}} {{price:%.2f}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := map[string]int{"host:port": 1, "price:%.2f": 1}; !reflect.DeepEqual(template.Placeholder(), want) {
+			t.Errorf("got %v  want:%v", template.Placeholder(), want)
+		}
+		got, err := template.ExecString(map[string]string{"host:port": "db:5432", "price:%.2f": "9.5"}, true)
+		if want := "db:5432 9.5"; got != want || err != nil {
+			t.Errorf("got %q %v  want:%q", got, err, want)
+		}
+	})
+
+	t.Run("case:ExecString parses numeric strings", func(t *testing.T) {
+		template, err := NewTemplate("{{price:%.2f}} {{age:%03d}} {{ok:%t}} {{hex:%x}} {{n:%x}} {{bad:%d}}", WithFormatSpecs())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecString(map[string]string{"price": "9.5", "age": "18", "ok": "true", "hex": "hi", "n": "255", "bad": "n/a"}, true)
+		if want := "9.50 018 true 6869 ff n/a"; got != want || err != nil {
+			t.Errorf("got %q %v  want:%q", got, err, want)
+		}
+	})
+
+	t.Run("case:ExecString applies fmt verbs", func(t *testing.T) {
+		template, err := NewTemplate("[{{name:%-6s}}]", WithFormatSpecs())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecString(map[string]string{"name": "tyltr"}, true)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "[tyltr ]"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+}
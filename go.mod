@@ -2,6 +2,12 @@ module github.com/tylitianrui/easytmpl
 
 go 1.23.0
 
-require github.com/valyala/fasttemplate v1.2.2
+require (
+	golang.org/x/tools v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
-require github.com/valyala/bytebufferpool v1.0.0 // indirect
+require (
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
@@ -0,0 +1,50 @@
+package easytmpl
+
+import (
+	"time"
+)
+
+// Hooks observes the rendering of a template, e.g. to record metrics or traces. Any field may be nil.
+// Hooks receive placeholder keys but never their values, and errors passed to them redact secret values,
+// so they are safe to log.
+type Hooks struct {
+	// OnRenderStart is called when ExecString or ExecuteFunc starts rendering.
+	OnRenderStart func(t *Template)
+
+	// OnRenderEnd is called when ExecString or ExecuteFunc returns, with the number of bytes written,
+	// the time spent rendering and the error returned, if any.
+	OnRenderEnd func(t *Template, size int, elapsed time.Duration, err error)
+
+	// OnMissingKey is called for each placeholder that has no value in the arguments of ExecString, ExecValues,
+	// ExecLists or ExecWithSourceMap, and for each named placeholder rendered by ExecArgs or AppendArgs.
+	// In strict mode it is called for the first missing key only.
+	OnMissingKey func(t *Template, key string)
+
+	// OnError is called when ExecString or ExecuteFunc returns an error.
+	OnError func(t *Template, err error)
+}
+
+// renderStart calls OnRenderStart and returns the start time of the render.
+func (h *Hooks) renderStart(t *Template) time.Time {
+	if h.OnRenderStart != nil {
+		h.OnRenderStart(t)
+	}
+	return time.Now()
+}
+
+// renderEnd calls OnRenderEnd, and OnError if err is not nil.
+func (h *Hooks) renderEnd(t *Template, start time.Time, size int, err error) {
+	if h.OnRenderEnd != nil {
+		h.OnRenderEnd(t, size, time.Since(start), err)
+	}
+	if err != nil && h.OnError != nil {
+		h.OnError(t, err)
+	}
+}
+
+// missingKey calls OnMissingKey, if the template has hooks.
+func (t *Template) missingKey(key string) {
+	if t.hooks != nil && t.hooks.OnMissingKey != nil {
+		t.hooks.OnMissingKey(t, key)
+	}
+}
@@ -0,0 +1,86 @@
+package easytmpl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTemplate_Hooks(t *testing.T) {
+	var events []string
+	hooks := Hooks{
+		OnRenderStart: func(t *Template) { events = append(events, "start") },
+		OnRenderEnd: func(t *Template, size int, elapsed time.Duration, err error) {
+			events = append(events, "end:"+strconv.Itoa(size))
+		},
+		OnMissingKey: func(t *Template, key string) { events = append(events, "missing:"+key) },
+		OnError:      func(t *Template, err error) { events = append(events, "error:"+err.Error()) },
+	}
+	template, err := NewTemplate("{{a}} {{b}}", WithHooks(hooks))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	if _, err := template.ExecString(map[string]string{"a": "1"}, false); err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := []string{"start", "missing:b", "end:7"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got %v  want:%v", events, want)
+	}
+
+	events = nil
+	if _, err := template.ExecString(map[string]string{"a": "1"}, true); err == nil {
+		t.Fatalf("got nil error")
+	}
+	if want := []string{"start", "missing:b", "end:0", "error:missing parameter"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got %v  want:%v", events, want)
+	}
+
+	events = nil
+	var bb bytes.Buffer
+	err = template.ExecuteFunc(&bb, func(w io.Writer, key string) (int, error) {
+		return 0, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatalf("got nil error")
+	}
+	if want := []string{"start", "end:0", "error:boom"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("got %v  want:%v", events, want)
+	}
+}
+
+func TestTemplate_HooksMissingKey(t *testing.T) {
+	var missing []string
+	template, err := NewTemplate("{{0}} {{b}}", WithHooks(Hooks{
+		OnMissingKey: func(t *Template, key string) { missing = append(missing, key) },
+	}))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	cases := []struct {
+		name string
+		exec func() error
+	}{
+		{"exec values", func() error { _, err := template.ExecValues(map[string]any{"0": 1}, false); return err }},
+		{"exec values strict", func() error { template.ExecValues(map[string]any{"0": 1}, true); return nil }},
+		{"exec lists", func() error { _, err := template.ExecLists(map[string][]string{"0": {"x"}}, false); return err }},
+		{"exec lists strict", func() error { template.ExecLists(map[string][]string{"0": {"x"}}, true); return nil }},
+		{"source map strict", func() error { template.ExecWithSourceMap(map[string]string{"0": "x"}, true); return nil }},
+		{"append args", func() error { _, err := template.AppendArgs(nil, "x"); return err }},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			missing = nil
+			if err := c.exec(); err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if want := []string{"b"}; !reflect.DeepEqual(missing, want) {
+				t.Errorf("got %v  want:%v", missing, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// Package httptmpl compiles HTTP request templates, written in the raw HTTP/1.1 text format, into easytmpl
+// templates and renders them into ready *http.Request values, e.g. to replay or load-test APIs:
+//
+//	POST /users/{{id}}?notify={{notify}} HTTP/1.1
+//	Host: {{host}}
+//	Content-Type: application/json
+//
+//	{"name": "{{name}}"}
+//
+// The method, the request target, the header names and values and the body are each compiled into a Template.
+package httptmpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+var (
+	// RequestLineInvalidError indicates that the first line of a request template is not `METHOD target [HTTP/1.1]`.
+	RequestLineInvalidError = errors.New("invalid request line")
+
+	// HeaderInvalidError indicates a header line of a request template without a colon.
+	HeaderInvalidError = errors.New("invalid header line")
+
+	// HeaderValueInvalidError indicates a rendered header name or value containing a CR or LF character.
+	HeaderValueInvalidError = errors.New("invalid header value")
+
+	// HostMissingError indicates that the request target is not an absolute URL, and that neither BaseURL
+	// nor a Host header gives the host to send the request to.
+	HostMissingError = errors.New("request has no host")
+)
+
+// Header is a header line of a request template.
+type Header struct {
+	Name *easytmpl.Template
+	// Value is nil if the value is empty.
+	Value *easytmpl.Template
+}
+
+// RequestTemplate is a compiled request template. It is safe for concurrent use once BaseURL is set,
+// so a single RequestTemplate can render the requests of a load test.
+type RequestTemplate struct {
+	Method *easytmpl.Template
+	// Target is the request target, an absolute URL or a path with an optional query.
+	Target *easytmpl.Template
+	Header []Header
+	// Body is nil if the request has no body.
+	Body *easytmpl.Template
+
+	// BaseURL, if set, is the URL a target that is not an absolute URL is resolved against,
+	// e.g. the URL of an httptest.Server. Otherwise the request is sent to the host of the Host header over HTTP.
+	BaseURL string
+
+	keys []string
+}
+
+// Compile compiles a request template. The request line holds the method, the target and an optional
+// protocol version, which is ignored; it is followed by header lines and, after an empty line, the body.
+// Lines may end with CRLF or LF. opts apply to each of the templates; it returns easytmpl.TemplateModeError
+// for options selecting Mustache mode or ShellSyntax, which are not supported.
+func Compile(text string, opts ...easytmpl.OptionHandler) (*RequestTemplate, error) {
+	rt := &RequestTemplate{}
+	placeholder := map[string]struct{}{}
+	compile := func(s string) (*easytmpl.Template, error) {
+		if strings.TrimSpace(s) == "" {
+			return nil, nil
+		}
+		t, err := easytmpl.NewTemplate(s, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if t.Mustache() || t.Syntax() != easytmpl.TagSyntax {
+			return nil, easytmpl.TemplateModeError
+		}
+		for k := range t.Placeholder() {
+			placeholder[k] = struct{}{}
+		}
+		return t, nil
+	}
+
+	offset := 0
+	line := func() string {
+		end := strings.IndexByte(text[offset:], '\n')
+		if end < 0 {
+			end = len(text) - offset
+		}
+		s := text[offset : offset+end]
+		offset = min(offset+end+1, len(text))
+		return strings.TrimSuffix(s, "\r")
+	}
+
+	start := offset
+	requestLine := line()
+	method, target, ok := strings.Cut(strings.TrimSpace(requestLine), " ")
+	target = strings.TrimSpace(target)
+	if i := strings.LastIndexByte(target, ' '); i >= 0 && strings.HasPrefix(target[i+1:], "HTTP/") {
+		target = strings.TrimSpace(target[:i])
+	}
+	if !ok || target == "" {
+		return nil, &easytmpl.Diagnostic{Err: RequestLineInvalidError, Source: text, Offset: start,
+			Hint: "start the request with a request line, e.g. GET /path HTTP/1.1"}
+	}
+	var err error
+	if rt.Method, err = compile(method); err != nil {
+		return nil, err
+	}
+	if rt.Target, err = compile(target); err != nil {
+		return nil, err
+	}
+
+	for offset < len(text) {
+		start := offset
+		l := line()
+		if l == "" {
+			break
+		}
+		name, value, ok := strings.Cut(l, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, &easytmpl.Diagnostic{Err: HeaderInvalidError, Source: text, Offset: start,
+				Hint: "write headers as Name: value, followed by an empty line before the body"}
+		}
+		var h Header
+		if h.Name, err = compile(strings.TrimSpace(name)); err != nil {
+			return nil, err
+		}
+		if h.Value, err = compile(strings.TrimSpace(value)); err != nil {
+			return nil, err
+		}
+		rt.Header = append(rt.Header, h)
+	}
+
+	if rt.Body, err = compile(text[offset:]); err != nil {
+		return nil, err
+	}
+	rt.keys = make([]string, 0, len(placeholder))
+	for k := range placeholder {
+		rt.keys = append(rt.keys, k)
+	}
+	sort.Strings(rt.keys)
+	return rt, nil
+}
+
+// Keys returns the sorted distinct placeholder keys of all the parts of the request template.
+func (rt *RequestTemplate) Keys() []string {
+	return append([]string(nil), rt.keys...)
+}
+
+// NewRequest resolves the placeholder keys of the request template with a single call to r and renders
+// the request. Values in the path of the target are escaped with url.PathEscape and values in its query
+// with url.QueryEscape; values in the host of an absolute URL, and a value starting the target such as base
+// in `{{base}}/users/{{id}}`, are not escaped, nor are query placeholders such as `{{?q}}`, whose value is
+// already encoded. Format and list specs in the target are applied as in ExecString. Header names and values
+// must not contain CR or LF characters. A Host header sets the Host of the request, and a Content-Length
+// header is ignored: the length of the rendered body is used.
+// It returns an error wrapping TemplateExecMissingParameterError if r does not resolve a key.
+func (rt *RequestTemplate) NewRequest(ctx context.Context, r easytmpl.BatchResolver) (*http.Request, error) {
+	args, err := r.Resolve(ctx, rt.Keys())
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range rt.keys {
+		if _, ok := args[k]; !ok {
+			return nil, fmt.Errorf("%w: %q", easytmpl.TemplateExecMissingParameterError, k)
+		}
+	}
+
+	method, err := render(rt.Method, args)
+	if err != nil {
+		return nil, err
+	}
+	u, err := rt.url(args)
+	if err != nil {
+		return nil, err
+	}
+	var body io.Reader
+	if rt.Body != nil {
+		s, err := rt.Body.ExecString(args, true)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(s)
+	}
+
+	header := make(http.Header, len(rt.Header))
+	host := ""
+	for _, h := range rt.Header {
+		name, err := render(h.Name, args)
+		if err != nil {
+			return nil, err
+		}
+		value, err := render(h.Value, args)
+		if err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(name, "\r\n") || strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("%w: %q", HeaderValueInvalidError, name)
+		}
+		switch http.CanonicalHeaderKey(name) {
+		case "Host":
+			host = value
+		case "Content-Length":
+		default:
+			header.Add(name, value)
+		}
+	}
+
+	if !u.IsAbs() {
+		switch {
+		case rt.BaseURL != "":
+			base, err := url.Parse(rt.BaseURL)
+			if err != nil {
+				return nil, err
+			}
+			u = base.ResolveReference(u)
+		case host != "":
+			u.Scheme, u.Host = "http", host
+		default:
+			return nil, HostMissingError
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	if host != "" {
+		req.Host = host
+	}
+	return req, nil
+}
+
+// url renders the request target, escaping the values according to the part of the URL they are in.
+func (rt *RequestTemplate) url(args map[string]string) (*url.URL, error) {
+	out, spans, err := rt.Target.ExecWithSourceMap(args, true)
+	if err != nil {
+		return nil, err
+	}
+	src := rt.Target.String()
+	var bb bytes.Buffer
+	for _, span := range spans {
+		v := out[span.Start:span.End]
+		if span.Kind == easytmpl.PlaceholderSpan {
+			// the static content and values before the placeholder have already been written to bb.
+			prefix := bb.Bytes()
+			switch {
+			case len(prefix) == 0:
+				// the value starts the target: it holds the scheme and authority, e.g. a base URL.
+			case isQuery(src, span):
+			case bytes.IndexByte(prefix, '?') >= 0:
+				v = url.QueryEscape(v)
+			case inAuthority(prefix):
+			default:
+				v = url.PathEscape(v)
+			}
+		}
+		bb.WriteString(v)
+	}
+	return url.Parse(bb.String())
+}
+
+// isQuery reports whether span is a query placeholder such as `{{?q}}` in the target source src;
+// its value is already encoded. The key of a query placeholder directly follows its `?`.
+func isQuery(src string, span easytmpl.Span) bool {
+	s := src[span.SourceStart:span.SourceEnd]
+	i := strings.Index(s, span.Key)
+	return i > 0 && s[i-1] == '?'
+}
+
+// inAuthority reports whether the target rendered so far ends in the authority of an absolute URL.
+func inAuthority(prefix []byte) bool {
+	i := bytes.Index(prefix, []byte("://"))
+	return i >= 0 && bytes.IndexByte(prefix[i+3:], '/') < 0
+}
+
+// render renders t in strict mode; a nil template renders as the empty string.
+func render(t *easytmpl.Template, args map[string]string) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	s, err := t.ExecString(args, true)
+	return strings.TrimSpace(s), err
+}
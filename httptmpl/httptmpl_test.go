@@ -0,0 +1,154 @@
+package httptmpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+// echo responds with the request as seen by the server.
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	fmt.Fprintf(w, "%s %s?%s\nhost=%s\nx-user=%s\nlength=%d\n%s", r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		r.Host, r.Header.Get("X-User"), r.ContentLength, body)
+}
+
+func values(args map[string]string) easytmpl.BatchResolver {
+	return easytmpl.BatchResolverFunc(func(_ context.Context, keys []string) (map[string]string, error) {
+		m := make(map[string]string, len(keys))
+		for _, k := range keys {
+			if v, ok := args[k]; ok {
+				m[k] = v
+			}
+		}
+		return m, nil
+	})
+}
+
+func TestRequestTemplate_NewRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(echo))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	cases := []struct {
+		name    string
+		tpl     string
+		baseURL string
+		args    map[string]string
+		opts    []easytmpl.OptionHandler
+		want    string
+	}{
+		{"post", "{{method}} /users/{{id}}?q={{q}} HTTP/1.1\r\nHost: {{host}}\r\nX-User: {{user}}\r\nContent-Length: 1\r\n\r\n{\"name\": \"{{name}}\"}",
+			"", map[string]string{"method": "POST", "id": "a b/c", "q": "x&y=z", "host": host, "user": "ada", "name": "Ada"}, nil,
+			"POST /users/a%20b%2Fc?q=x%26y%3Dz\nhost=" + host + "\nx-user=ada\nlength=15\n{\"name\": \"Ada\"}"},
+		{"get without version", "GET /items/{{id}}\nHost: " + host + "\n", "", map[string]string{"id": "7"}, nil,
+			"GET /items/7?\nhost=" + host + "\nx-user=\nlength=0\n"},
+		{"base url and virtual host", "GET /v?n={{n}}\nHost: api.example.com", server.URL, map[string]string{"n": "1"}, nil,
+			"GET /v?n=1\nhost=api.example.com\nx-user=\nlength=0\n"},
+		{"absolute url", "GET http://{{host}}/p/{{p}}", "", map[string]string{"host": host, "p": "ä"}, nil,
+			"GET /p/%C3%A4?\nhost=" + host + "\nx-user=\nlength=0\n"},
+		{"base url placeholder", "GET {{base}}/users/{{id}}", "", map[string]string{"base": server.URL, "id": "a b"}, nil,
+			"GET /users/a%20b?\nhost=" + host + "\nx-user=\nlength=0\n"},
+		{"specs", "GET /items/{{n:%03d}}?{{?q}}&tags={{tags,}}\nHost: " + host, "", map[string]string{"n": "7", "q": "a b", "tags": "x&y"},
+			[]easytmpl.OptionHandler{easytmpl.WithFormatSpecs(), easytmpl.WithListSpecs()},
+			"GET /items/007?q=a+b&tags=x%26y\nhost=" + host + "\nx-user=\nlength=0\n"},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			rt, err := Compile(c.tpl, c.opts...)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			rt.BaseURL = c.baseURL
+			req, err := rt.NewRequest(context.Background(), values(c.args))
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			defer resp.Body.Close()
+			got, _ := io.ReadAll(resp.Body)
+			if string(got) != c.want {
+				t.Errorf("got %q  want:%q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRequestTemplate_Errors(t *testing.T) {
+	t.Run("case:request line", func(t *testing.T) {
+		_, err := Compile("GET\nHost: x")
+		var d *easytmpl.Diagnostic
+		if !errors.Is(err, RequestLineInvalidError) || !errors.As(err, &d) {
+			t.Errorf("got %v  want:%v", err, RequestLineInvalidError)
+		}
+	})
+
+	t.Run("case:header line", func(t *testing.T) {
+		_, err := Compile("GET /\nHost x")
+		var d *easytmpl.Diagnostic
+		if !errors.Is(err, HeaderInvalidError) || !errors.As(err, &d) {
+			t.Fatalf("got %v  want:%v", err, HeaderInvalidError)
+		}
+		if line, _ := d.Position(); line != 2 {
+			t.Errorf("got %v  want:%v", line, 2)
+		}
+	})
+
+	rt, err := Compile("GET /{{p}}\nHost: h\nX-User: {{user}}")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if got, want := rt.Keys(), []string{"p", "user"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v  want:%v", got, want)
+	}
+
+	t.Run("case:header injection", func(t *testing.T) {
+		_, err := rt.NewRequest(context.Background(), values(map[string]string{"p": "x", "user": "a\r\nX-Admin: 1"}))
+		if !errors.Is(err, HeaderValueInvalidError) {
+			t.Errorf("got %v  want:%v", err, HeaderValueInvalidError)
+		}
+	})
+
+	t.Run("case:missing", func(t *testing.T) {
+		_, err := rt.NewRequest(context.Background(), values(map[string]string{"p": "x"}))
+		if !errors.Is(err, easytmpl.TemplateExecMissingParameterError) {
+			t.Errorf("got %v  want:%v", err, easytmpl.TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:resolver", func(t *testing.T) {
+		want := errors.New("backend down")
+		_, err := rt.NewRequest(context.Background(), easytmpl.BatchResolverFunc(func(context.Context, []string) (map[string]string, error) {
+			return nil, want
+		}))
+		if !errors.Is(err, want) {
+			t.Errorf("got %v  want:%v", err, want)
+		}
+	})
+
+	t.Run("case:unsupported modes", func(t *testing.T) {
+		for _, opt := range []easytmpl.OptionHandler{easytmpl.WithMustache(), easytmpl.WithSyntax(easytmpl.ShellSyntax)} {
+			if _, err := Compile("GET /$p\nHost: h", opt); !errors.Is(err, easytmpl.TemplateModeError) {
+				t.Errorf("got %v  want:%v", err, easytmpl.TemplateModeError)
+			}
+		}
+	})
+
+	t.Run("case:no host", func(t *testing.T) {
+		rt, _ := Compile("GET /x")
+		if _, err := rt.NewRequest(context.Background(), values(nil)); !errors.Is(err, HostMissingError) {
+			t.Errorf("got %v  want:%v", err, HostMissingError)
+		}
+	})
+}
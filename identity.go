@@ -0,0 +1,65 @@
+package easytmpl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// TemplateError wraps the errors returned by a template created with WithName or WithVersion,
+// so that errors from a process rendering many templates tell which one failed.
+// errors.Is and errors.As see through it to the wrapped error.
+type TemplateError struct {
+	Name    string
+	Version string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *TemplateError) Error() string {
+	s := "template " + strconv.Quote(e.Name)
+	if e.Version != "" {
+		s += " (version " + e.Version + ")"
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// Name returns the name set with WithName.
+func (t *Template) Name() string {
+	return t.name
+}
+
+// Version returns the version set with WithVersion.
+func (t *Template) Version() string {
+	return t.version
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of the template content, computed by NewTemplate.
+func (t *Template) Checksum() string {
+	return t.checksum
+}
+
+// checksumOf returns the hex encoded SHA-256 checksum of content.
+func checksumOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// wrapError wraps *errp in a TemplateError if the template has a name or a version.
+// It is meant to be deferred by the exported methods that return errors.
+func (t *Template) wrapError(errp *error) {
+	if *errp == nil || t.name == "" && t.version == "" {
+		return
+	}
+	var te *TemplateError
+	if errors.As(*errp, &te) {
+		return
+	}
+	*errp = &TemplateError{Name: t.name, Version: t.version, Err: *errp}
+}
@@ -0,0 +1,92 @@
+package easytmpl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTemplate_Identity(t *testing.T) {
+	template, err := NewTemplate("hello {{name}}", WithName("greeting"), WithVersion("v2"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if got, want := template.Name(), "greeting"; got != want {
+		t.Errorf("got %v  want:%v", got, want)
+	}
+	if got, want := template.Version(), "v2"; got != want {
+		t.Errorf("got %v  want:%v", got, want)
+	}
+	if got := template.Checksum(); len(got) != 64 {
+		t.Errorf("got %v  want a sha256 hex checksum", got)
+	}
+
+	t.Run("case:same content same checksum", func(t *testing.T) {
+		other, err := NewTemplate("hello {{name}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if other.Checksum() != template.Checksum() {
+			t.Errorf("got %v  want:%v", other.Checksum(), template.Checksum())
+		}
+	})
+
+	t.Run("case:bind updates checksum", func(t *testing.T) {
+		bound := template.Bind(map[string]string{"name": "world"})
+		if bound.Checksum() == template.Checksum() {
+			t.Errorf("got unchanged checksum %v", bound.Checksum())
+		}
+		if bound.Name() != "greeting" || bound.Version() != "v2" {
+			t.Errorf("got %v %v  want:greeting v2", bound.Name(), bound.Version())
+		}
+	})
+
+	t.Run("case:errors are wrapped", func(t *testing.T) {
+		_, err := template.ExecString(nil, true)
+		if !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Fatalf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+		var te *TemplateError
+		if !errors.As(err, &te) || te.Name != "greeting" || te.Version != "v2" {
+			t.Fatalf("got %#v  want a TemplateError", err)
+		}
+		if got, want := err.Error(), `template "greeting" (version v2): missing parameter`; got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+
+		failed := errors.New("backend down")
+		err = template.ExecBatch(context.Background(), io.Discard, BatchResolverFunc(func(ctx context.Context, keys []string) (map[string]string, error) {
+			return nil, failed
+		}), false)
+		if !errors.As(err, &te) || !errors.Is(err, failed) {
+			t.Errorf("got %v  want a TemplateError", err)
+		}
+		if _, err := template.ExecValues(nil, true); !errors.As(err, &te) {
+			t.Errorf("got %v  want a TemplateError", err)
+		}
+	})
+
+	t.Run("case:option errors are wrapped", func(t *testing.T) {
+		_, err := NewTemplate("{{{{a}}", WithName("broken"), WithMaxTemplateSize(3))
+		var le *LimitExceededError
+		if !errors.As(err, &le) {
+			t.Fatalf("got %v  want a LimitExceededError", err)
+		}
+		if got, want := err.Error(), `template "broken": `+le.Error(); got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+	})
+
+	t.Run("case:unnamed errors are not wrapped", func(t *testing.T) {
+		other, err := NewTemplate("hello {{name}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = other.ExecString(nil, true)
+		var te *TemplateError
+		if !errors.Is(err, TemplateExecMissingParameterError) || errors.As(err, &te) {
+			t.Errorf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package easytmpl
+
+import (
+	"bytes"
+	"io"
+)
+
+// parseIndents computes, for each placeholder rendered with indentation, the leading whitespace of the line
+// containing it. t.indents is left nil if no placeholder needs indentation.
+func (t *Template) parseIndents() {
+	t.indents = nil
+	if !t.indentAll && len(t.indentKeys) == 0 {
+		return
+	}
+	for i := range t.args {
+		if !t.indentAll {
+			if _, ok := t.indentKeys[b2s(t.args[i])]; !ok {
+				continue
+			}
+		}
+		pos := t.contentIntervalIdx[i][1]
+		start := bytes.LastIndexByte(t.content[:pos], '\n') + 1
+		end := start
+		for end < pos && (t.content[end] == ' ' || t.content[end] == '\t') {
+			end++
+		}
+		if end == start {
+			continue
+		}
+		if t.indents == nil {
+			t.indents = make([][]byte, len(t.args))
+		}
+		t.indents[i] = t.content[start:end:end]
+	}
+}
+
+// indentWriter prefixes each line but the first of the value written through it with prefix.
+// The prefix is written lazily, so a value ending with a newline does not leave a whitespace-only line,
+// and blank lines of the value stay empty.
+type indentWriter struct {
+	w       io.Writer
+	prefix  []byte
+	pending bool
+}
+
+// Write implements the io.Writer interface. It returns the number of bytes of p written, not counting the prefixes.
+func (iw *indentWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		if iw.pending && p[0] != '\n' && p[0] != '\r' {
+			if _, err := iw.w.Write(iw.prefix); err != nil {
+				return n, err
+			}
+		}
+		iw.pending = false
+		k := bytes.IndexByte(p, '\n') + 1
+		if k == 0 {
+			k = len(p)
+		} else {
+			iw.pending = true
+		}
+		m, err := iw.w.Write(p[:k])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[k:]
+	}
+	return n, nil
+}
+
+// newIndentWriter returns the indentWriter reused for the placeholders of a render, or nil if no placeholder
+// is rendered with indentation, so that templates without indentation do not allocate one.
+func (t *Template) newIndentWriter() *indentWriter {
+	if t.indents == nil {
+		return nil
+	}
+	return &indentWriter{}
+}
+
+// placeholderWriter returns the writer the value of the placeholder at index i is written to:
+// w itself, or iw, from newIndentWriter, writing to w if the placeholder is rendered with indentation.
+func (t *Template) placeholderWriter(iw *indentWriter, w io.Writer, i int) io.Writer {
+	if t.indents == nil || t.indents[i] == nil {
+		return w
+	}
+	iw.w, iw.prefix, iw.pending = w, t.indents[i], false
+	return iw
+}
@@ -0,0 +1,131 @@
+package easytmpl
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTemplate_WithIndent(t *testing.T) {
+	cases := []struct {
+		name string
+		tpl  string
+		opts []OptionHandler
+		args map[string]string
+		want string
+	}{
+		{"yaml", "spec:\n  containers:\n    {{containers}}\n", []OptionHandler{WithIndent()},
+			map[string]string{"containers": "- name: web\n  image: nginx"},
+			"spec:\n  containers:\n    - name: web\n      image: nginx\n"},
+		{"tabs", "func f() {\n\t{{body}}\n}", []OptionHandler{WithIndent()},
+			map[string]string{"body": "a()\nb()"},
+			"func f() {\n\ta()\n\tb()\n}"},
+		{"trailing newline", "  {{v}}end", []OptionHandler{WithIndent()},
+			map[string]string{"v": "a\nb\n"},
+			"  a\n  b\nend"},
+		{"blank lines", "  {{v}}", []OptionHandler{WithIndent()},
+			map[string]string{"v": "a\n\nb\r\n\r\nc"},
+			"  a\n\n  b\r\n\r\n  c"},
+		{"text before the placeholder", "  key: {{v}}", []OptionHandler{WithIndent()},
+			map[string]string{"v": "a\nb"},
+			"  key: a\n  b"},
+		{"no indentation", "{{v}}", []OptionHandler{WithIndent()},
+			map[string]string{"v": "a\nb"},
+			"a\nb"},
+		{"keys", "  {{a}}\n  {{b}}", []OptionHandler{WithIndent("b")},
+			map[string]string{"a": "1\n2", "b": "3\n4"},
+			"  1\n2\n  3\n  4"},
+		{"disabled", "  {{v}}", nil,
+			map[string]string{"v": "a\nb"},
+			"  a\nb"},
+		{"auto fill", "  {{v}}", []OptionHandler{WithIndent(), WithAutoFill("x\ny")},
+			nil,
+			"  x\n  y"},
+		{"limits", "  {{v}}", []OptionHandler{WithIndent(), WithMaxOutputSize(64)},
+			map[string]string{"v": "a\nb"},
+			"  a\n  b"},
+		{"shell", "  ${V}", []OptionHandler{WithIndent(), WithSyntax(ShellSyntax)},
+			map[string]string{"V": "a\nb"},
+			"  a\n  b"},
+		{"mustache", "  {{v}}", []OptionHandler{WithIndent(), WithMustache()},
+			map[string]string{"v": "a\nb"},
+			"  a\nb"},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := NewTemplate(c.tpl, c.opts...)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			got, err := template.ExecString(c.args, false)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q  want:%q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("case:exec values", func(t *testing.T) {
+		template, _ := NewTemplate("  {{v}} {{n:%d}}", WithIndent(), WithFormatSpecs())
+		got, err := template.ExecValues(map[string]any{"v": "a\nb", "n": 1}, true)
+		if err != nil || got != "  a\n  b 1" {
+			t.Errorf("got %q %v  want:%q", got, err, "  a\n  b 1")
+		}
+	})
+
+	t.Run("case:exec args", func(t *testing.T) {
+		template, _ := NewTemplate("  {{0}}", WithIndent())
+		got, err := template.ExecArgs("a\nb")
+		if err != nil || got != "  a\n  b" {
+			t.Errorf("got %q %v  want:%q", got, err, "  a\n  b")
+		}
+	})
+
+	t.Run("case:max value size", func(t *testing.T) {
+		template, _ := NewTemplate("    {{v}}", WithIndent(), WithMaxValueSize(6))
+		var le *LimitExceededError
+		if _, err := template.ExecString(map[string]string{"v": "a\nb"}, false); !errors.As(err, &le) || le.Limit != ValueLimit {
+			t.Errorf("got %v  want:%v", err, ValueLimit)
+		}
+	})
+
+	t.Run("case:bind", func(t *testing.T) {
+		template, _ := NewTemplate("  {{a}}\n  {{b}}", WithIndent())
+		bound := template.Bind(map[string]string{"a": "1\n2"})
+		if s := bound.String(); s != "  1\n  2\n  {{b}}" {
+			t.Errorf("got %q  want:%q", s, "  1\n  2\n  {{b}}")
+		}
+		got, _ := bound.ExecString(map[string]string{"b": "3\n4"}, true)
+		if got != "  1\n  2\n  3\n  4" {
+			t.Errorf("got %q  want:%q", got, "  1\n  2\n  3\n  4")
+		}
+	})
+
+	t.Run("case:binary", func(t *testing.T) {
+		for _, opt := range []OptionHandler{WithIndent(), WithIndent("v")} {
+			template, _ := NewTemplate("{{! note }}  {{v}}", opt)
+			data, err := template.MarshalBinary()
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			var got Template
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatalf("error %v", err)
+			}
+			s, _ := got.ExecString(map[string]string{"v": "a\nb"}, true)
+			if s != "  a\n  b" {
+				t.Errorf("got %q  want:%q", s, "  a\n  b")
+			}
+		}
+	})
+}
+
+func TestTemplate_IndentAllocs(t *testing.T) {
+	template, _ := NewTemplate("https://{{domain}}.com?name={{name}}")
+	f := func(w io.Writer, key string) (int, error) { return 0, nil }
+	if n := testing.AllocsPerRun(100, func() { template.ExecuteFunc(io.Discard, f) }); n != 0 {
+		t.Errorf("got %v allocs  want:0", n)
+	}
+}
@@ -0,0 +1,112 @@
+package easytmpl
+
+import (
+	"io"
+	"strconv"
+)
+
+const (
+	// TemplateLimit is the limit set by WithMaxTemplateSize.
+	TemplateLimit = "template"
+
+	// OutputLimit is the limit set by WithMaxOutputSize.
+	OutputLimit = "output"
+
+	// ValueLimit is the limit set by WithMaxValueSize.
+	ValueLimit = "value"
+)
+
+// LimitExceededError is returned when a template source, a rendered output or a single placeholder value
+// is larger than the limit configured with WithMaxTemplateSize, WithMaxOutputSize or WithMaxValueSize.
+type LimitExceededError struct {
+	// Limit is one of TemplateLimit, OutputLimit and ValueLimit.
+	Limit string
+	// Max is the configured limit in bytes.
+	Max int
+	// Key is the key of the placeholder being written when the limit was crossed.
+	Key string
+	// Index is the index of that placeholder, or -1 if the limit was crossed by static content or by the template source.
+	Index int
+}
+
+// Error implements the error interface.
+func (e *LimitExceededError) Error() string {
+	size := strconv.Itoa(e.Max) + " bytes"
+	switch {
+	case e.Limit == ValueLimit:
+		return "value of placeholder " + strconv.Quote(e.Key) + " exceeds limit of " + size
+	case e.Limit == TemplateLimit:
+		return "template size exceeds limit of " + size
+	case e.Index >= 0:
+		return "output exceeds limit of " + size + " at placeholder " + strconv.Quote(e.Key)
+	}
+	return "output exceeds limit of " + size
+}
+
+// limitWriter is an io.Writer that enforces the output and value size limits of a template.
+// A write that would cross a limit is rejected as a whole, and every later write fails with the same error.
+type limitWriter struct {
+	w     io.Writer
+	t     *Template
+	n     int
+	value int
+	index int
+	key   string
+	err   error
+}
+
+// newLimitWriter returns w wrapped in a limitWriter if the template has output or value limits,
+// or w itself otherwise.
+func (t *Template) newLimitWriter(w io.Writer) io.Writer {
+	if t.maxOutputSize == 0 && t.maxValueSize == 0 {
+		return w
+	}
+	return &limitWriter{w: w, t: t, index: -1}
+}
+
+// placeholder marks the start of the value of the placeholder at index i with key; an index of -1 marks static content.
+func (l *limitWriter) placeholder(i int, key string) {
+	l.index, l.key, l.value = i, key, 0
+}
+
+// Write implements the io.Writer interface.
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if l.index >= 0 && l.t.maxValueSize > 0 && l.value+len(p) > l.t.maxValueSize {
+		l.err = &LimitExceededError{Limit: ValueLimit, Max: l.t.maxValueSize, Key: l.key, Index: l.index}
+		return 0, l.err
+	}
+	if l.t.maxOutputSize > 0 && l.n+len(p) > l.t.maxOutputSize {
+		l.err = &LimitExceededError{Limit: OutputLimit, Max: l.t.maxOutputSize, Key: l.key, Index: l.index}
+		return 0, l.err
+	}
+	n, err := l.w.Write(p)
+	l.n += n
+	l.value += n
+	return n, err
+}
+
+// execLimited is exec for templates with output or value limits.
+func (t *Template) execLimited(lw *limitWriter, f func(w io.Writer, i int) error) error {
+	iw := t.newIndentWriter()
+	for i := 0; i < len(t.contentIntervalIdx)-1; i++ {
+		lw.placeholder(-1, "")
+		lw.Write(t.content[t.contentIntervalIdx[i][0]:t.contentIntervalIdx[i][1]])
+		if lw.err != nil {
+			return lw.err
+		}
+		lw.placeholder(i, b2s(t.args[i]))
+		err := f(t.placeholderWriter(iw, lw, i), i)
+		if lw.err != nil {
+			return lw.err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	lw.placeholder(-1, "")
+	lw.Write(t.content[t.contentIntervalIdx[len(t.contentIntervalIdx)-1][0]:])
+	return lw.err
+}
@@ -0,0 +1,84 @@
+package easytmpl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplate_Limits(t *testing.T) {
+	t.Run("case:template size", func(t *testing.T) {
+		_, err := NewTemplate("{{name}} is too long", WithMaxTemplateSize(8))
+		var le *LimitExceededError
+		if !errors.As(err, &le) || le.Limit != TemplateLimit || le.Max != 8 {
+			t.Fatalf("got %v", err)
+		}
+	})
+
+	t.Run("case:value size", func(t *testing.T) {
+		template, err := NewTemplate("a={{a}} b={{b}}", WithMaxValueSize(4))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecString(map[string]string{"a": "1234", "b": "12345"}, true)
+		var le *LimitExceededError
+		if !errors.As(err, &le) || le.Limit != ValueLimit || le.Key != "b" || le.Index != 1 {
+			t.Fatalf("got %v", err)
+		}
+		if want := `value of placeholder "b" exceeds limit of 4 bytes`; err.Error() != want {
+			t.Errorf("got %q  want:%q", err.Error(), want)
+		}
+		if want := "a=1234 b="; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:output size crossed by a placeholder", func(t *testing.T) {
+		template, err := NewTemplate("a={{a}} b={{b}}", WithMaxOutputSize(10))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecValues(map[string]any{"a": 1, "b": "long value"}, true)
+		var le *LimitExceededError
+		if !errors.As(err, &le) || le.Limit != OutputLimit || le.Key != "b" {
+			t.Fatalf("got %v", err)
+		}
+		if _, err := template.ExecArgs(); err == nil {
+			t.Fatalf("got nil error for output of %d bytes", len("a={{a}} b={{b}}"))
+		}
+	})
+
+	t.Run("case:output size crossed by static content", func(t *testing.T) {
+		template, err := NewTemplate("{{a}} and some trailing text", WithMaxOutputSize(10))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecString(map[string]string{"a": "1"}, true)
+		var le *LimitExceededError
+		if !errors.As(err, &le) || le.Limit != OutputLimit || le.Index != -1 {
+			t.Fatalf("got %v", err)
+		}
+	})
+
+	t.Run("case:mustache mode", func(t *testing.T) {
+		template, err := NewTemplate("{{#items}}{{.}},{{/items}}", WithMustache(), WithMaxValueSize(3))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.RenderString(map[string]any{"items": []string{"a", "bcde"}})
+		var le *LimitExceededError
+		if !errors.As(err, &le) || le.Limit != ValueLimit || le.Key != "." {
+			t.Fatalf("got %v", err)
+		}
+	})
+
+	t.Run("case:within limits", func(t *testing.T) {
+		template, err := NewTemplate("a={{a}}", WithMaxOutputSize(3), WithMaxValueSize(1), WithMaxTemplateSize(7))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecString(map[string]string{"a": "1"}, true)
+		if err != nil || got != "a=1" {
+			t.Errorf("got %q, %v", got, err)
+		}
+	})
+}
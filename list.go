@@ -0,0 +1,178 @@
+package easytmpl
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// listSpec is the list spec of a placeholder: `{{tags,}}` joins the values of a list with sep,
+// `{{?params}}` expands its value into a URL-encoded query string.
+type listSpec struct {
+	sep   string
+	query bool
+}
+
+// parseLists strips the list spec from each placeholder key. A key starting with `?` is a query placeholder;
+// a key followed by `,` is a list placeholder, joined with the text after the comma, or with `,` if there is none.
+// With WithFormatSpecs, a comma following a `:` belongs to the format spec, e.g. `{{ts:Jan 2, 2006}}`.
+// It is only called for templates created with WithListSpecs; t.lists is left nil if no placeholder has a list spec.
+func (t *Template) parseLists() {
+	for i, a := range t.args {
+		var spec listSpec
+		if len(a) > 1 && a[0] == '?' {
+			spec.query = true
+			t.args[i] = a[1:]
+		} else {
+			k := bytes.IndexByte(a, ',')
+			if k <= 0 || t.formatSpecs && bytes.IndexByte(a[:k], ':') >= 0 {
+				continue
+			}
+			spec.sep = string(a[k+1:])
+			if spec.sep == "" {
+				spec.sep = ","
+			}
+			t.args[i] = a[:k]
+		}
+		if t.lists == nil {
+			t.lists = make([]listSpec, len(t.args))
+		}
+		t.lists[i] = spec
+	}
+}
+
+// list returns the list spec of the placeholder at index i.
+func (t *Template) list(i int) listSpec {
+	if t.lists == nil {
+		return listSpec{}
+	}
+	return t.lists[i]
+}
+
+// appendArg appends the value v of the placeholder at index i to dst, applying its list and format specs.
+//
+// For a list placeholder, the elements of a slice or array are formatted and joined; other values are
+// formatted as with no list spec. For a query placeholder, a url.Values, a map[string][]string or a map
+// with string keys is encoded with url.Values.Encode, sorted by key; any other value v is encoded as
+// `key=v`, repeated for each element of a slice. Empty values render nothing.
+func (t *Template) appendArg(dst []byte, i int, v any) []byte {
+	spec := t.list(i)
+	switch {
+	case spec.query:
+		return t.appendQuery(dst, i, v)
+	case spec.sep != "":
+		if rv := reflect.ValueOf(v); isList(rv) {
+			for j := 0; j < rv.Len(); j++ {
+				if j > 0 {
+					dst = append(dst, spec.sep...)
+				}
+				dst = appendValue(dst, rv.Index(j).Interface(), t.format(i))
+			}
+			return dst
+		}
+	}
+	return appendValue(dst, v, t.format(i))
+}
+
+// appendQuery appends the value v of the query placeholder at index i to dst.
+func (t *Template) appendQuery(dst []byte, i int, v any) []byte {
+	var q url.Values
+	switch x := v.(type) {
+	case url.Values:
+		q = x
+	case map[string][]string:
+		q = x
+	default:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+			q = make(url.Values, rv.Len())
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(a, b int) bool { return keys[a].String() < keys[b].String() })
+			for _, k := range keys {
+				q[k.String()] = queryValues(rv.MapIndex(k), t.format(i))
+			}
+		} else {
+			q = url.Values{b2s(t.args[i]): queryValues(rv, t.format(i))}
+		}
+	}
+	return append(dst, q.Encode()...)
+}
+
+// queryValues formats the elements of the list rv, or rv itself if it is not a list.
+func queryValues(rv reflect.Value, f valueFormat) []string {
+	if rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	if !isList(rv) {
+		return []string{string(appendValue(nil, rv.Interface(), f))}
+	}
+	vs := make([]string, rv.Len())
+	for j := range vs {
+		vs[j] = string(appendValue(nil, rv.Index(j).Interface(), f))
+	}
+	return vs
+}
+
+// isList reports whether rv is a slice or an array, other than a byte slice.
+func isList(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// ExecLists renders the template with list values, e.g. from url.Values.
+// With WithListSpecs, list placeholders such as `{{tags,}}` join the values of their key with the separator, e.g. `a,b,c`;
+// query placeholders such as `{{?tags}}` expand them into an encoded query string, e.g. `tags=a&tags=b`.
+// Other placeholders are replaced with the first value of their key, as url.Values.Get does.
+// If strict is true, it returns an error if any placeholder does not have a corresponding entry in args;
+// otherwise those placeholders are auto-filled or remain unchanged, as in ExecString.
+// It returns TemplateModeError for templates in Mustache mode or in ShellSyntax.
+func (t *Template) ExecLists(args map[string][]string, strict bool) (_ string, err error) {
+	defer t.wrapError(&err)
+	if t.mustache || t.syntax != TagSyntax {
+		return "", TemplateModeError
+	}
+	if strict {
+		for i, a := range t.args {
+			if _, ok := args[b2s(a)]; !ok {
+				t.missingKey(b2s(a))
+				return "", t.missingParameter(i)
+			}
+		}
+	}
+
+	var bb bytes.Buffer
+	bb.Grow(max(len(t.content)*2, t.capacity))
+	var scratch []byte
+	err = t.exec(&bb, func(w io.Writer, i int) error {
+		var err error
+		if vs, ok := args[b2s(t.args[i])]; ok {
+			if t.lists == nil || t.lists[i] == (listSpec{}) {
+				if len(vs) > 0 {
+					scratch = appendValue(scratch[:0], vs[0], t.format(i))
+					_, err = w.Write(scratch)
+				}
+			} else {
+				scratch = t.appendArg(scratch[:0], i, vs)
+				_, err = w.Write(scratch)
+			}
+		} else if t.autoFill != nil {
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(*t.autoFill)
+		} else {
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(t.placeholder(i))
+		}
+		return err
+	})
+	return bb.String(), err
+}
@@ -0,0 +1,130 @@
+package easytmpl
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_ExecLists(t *testing.T) {
+	cases := []struct {
+		name string
+		tpl  string
+		args url.Values
+		want string
+	}{
+		{"join", "/search?tags={{tags,}}", url.Values{"tags": {"a", "b", "c"}}, "/search?tags=a,b,c"},
+		{"separator", "{{tags,; }}", url.Values{"tags": {"a", "b"}}, "a; b"},
+		{"query", "/search?{{?tags}}", url.Values{"tags": {"a b", "c&d"}}, "/search?tags=a+b&tags=c%26d"},
+		{"first value", "{{q}}", url.Values{"q": {"x", "y"}}, "x"},
+		{"empty", "[{{tags,}}][{{?q}}][{{p}}]", url.Values{"tags": nil, "q": {}, "p": nil}, "[][][]"},
+		{"missing", "{{tags,}}", url.Values{}, "{{tags,}}"},
+		{"format spec", "{{ts:Jan 2, 2006}}", url.Values{"ts": {"x"}}, "x"},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := NewTemplate(c.tpl, WithFormatSpecs(), WithListSpecs())
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			got, err := template.ExecLists(c.args, false)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %q  want:%q", got, c.want)
+			}
+		})
+	}
+
+	t.Run("case:keys", func(t *testing.T) {
+		template, _ := NewTemplate("{{a,}} {{?b}} {{c:%d}} {{ts:Jan 2, 2006}}", WithFormatSpecs(), WithListSpecs())
+		want := map[string]int{"a": 1, "b": 1, "c": 1, "ts": 1}
+		if got := template.Placeholder(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+	})
+
+	t.Run("case:without list specs", func(t *testing.T) {
+		template, _ := NewTemplate("x={{a,b}} {{?q}}")
+		if want := map[string]int{"a,b": 1, "?q": 1}; !reflect.DeepEqual(template.Placeholder(), want) {
+			t.Errorf("got %v  want:%v", template.Placeholder(), want)
+		}
+		got, _ := template.ExecString(map[string]string{"a,b": "V", "a": "A", "?q": "hello world"}, true)
+		if want := "x=V hello world"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:strict", func(t *testing.T) {
+		template, _ := NewTemplate("{{a,}}{{?b}}", WithListSpecs())
+		if _, err := template.ExecLists(url.Values{"a": {"1"}}, true); !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Errorf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:mode", func(t *testing.T) {
+		template, _ := NewTemplate("{{a}}", WithMustache())
+		if _, err := template.ExecLists(nil, false); !errors.Is(err, TemplateModeError) {
+			t.Errorf("got %v  want:%v", err, TemplateModeError)
+		}
+	})
+}
+
+func TestTemplate_ListValues(t *testing.T) {
+	template, err := NewTemplate("/items?ids={{ids,}}&{{?params}}&{{?page}}&{{?prices:%.1f}}", WithFormatSpecs(), WithListSpecs())
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	args := map[string]any{
+		"ids":    []int{1, 2, 3},
+		"params": map[string]any{"q": "a b", "tag": []string{"x", "y"}},
+		"page":   2,
+		"prices": []float64{1, 2.5},
+	}
+	want := "/items?ids=1,2,3&q=a+b&tag=x&tag=y&page=2&prices=1.0&prices=2.5"
+	got, err := template.ExecValues(args, true)
+	if err != nil || got != want {
+		t.Errorf("got %q %v  want:%q", got, err, want)
+	}
+
+	t.Run("case:url values", func(t *testing.T) {
+		got, _ := template.ExecValues(map[string]any{"params": url.Values{"b": {"2"}, "a": {"1"}}}, false)
+		want := "/items?ids={{ids,}}&a=1&b=2&{{?page}}&{{?prices:%.1f}}"
+		if got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:exec string", func(t *testing.T) {
+		got, _ := template.ExecString(map[string]string{"ids": "1,2", "page": "a&b"}, false)
+		want := "/items?ids=1,2&{{?params}}&page=a%26b&{{?prices:%.1f}}"
+		if got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:bind", func(t *testing.T) {
+		bound := template.Bind(map[string]string{"page": "3"})
+		got, _ := bound.ExecLists(url.Values{"ids": {"1", "2"}}, false)
+		want := "/items?ids=1,2&{{?params}}&page=3&{{?prices:%.1f}}"
+		if got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:binary", func(t *testing.T) {
+		data, err := template.MarshalBinary()
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		var decoded Template
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, _ := decoded.ExecValues(args, true); got != want || !decoded.listSpecs {
+			t.Errorf("got %q %v  want:%q true", got, decoded.listSpecs, want)
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package easytmpl
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CharClassInvalidError indicates that a character class passed to WithCharClass is malformed.
+var CharClassInvalidError = errors.New("invalid character class")
+
+// newPatternConstraint returns a constraint that accepts values fully matched by the regular expression pattern.
+func newPatternConstraint(pattern string) (func(string) bool, error) {
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// newCharClassConstraint returns a constraint that accepts values consisting only of bytes in class.
+// class uses the syntax of a bracket expression without the brackets, e.g. `a-z0-9_` or `^/`,
+// and is limited to ASCII characters.
+func newCharClassConstraint(class string) (func(string) bool, error) {
+	var set [256]bool
+	negate := strings.HasPrefix(class, "^")
+	if negate {
+		class = class[1:]
+	}
+	if class == "" {
+		return nil, CharClassInvalidError
+	}
+	for i := 0; i < len(class); i++ {
+		lo, hi := class[i], class[i]
+		if i+2 < len(class) && class[i+1] == '-' {
+			hi = class[i+2]
+			i += 2
+		}
+		if lo > hi || hi >= 0x80 {
+			return nil, CharClassInvalidError
+		}
+		for c := int(lo); c <= int(hi); c++ {
+			set[c] = true
+		}
+	}
+	if negate {
+		for c := range set {
+			set[c] = !set[c]
+		}
+	}
+	return func(s string) bool {
+		for i := 0; i < len(s); i++ {
+			if !set[s[i]] {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// Match extracts the values of the placeholders from s, a string rendered from the template.
+// The static content between placeholders is used as anchors; when an anchor occurs several times,
+// the shortest value that lets the rest of s match is chosen. Every value must be non-empty and satisfy
+// the placeholder's constraint (see WithPattern and WithCharClass), and a key used more than once must
+// match the same value at each occurrence. It reports false if s cannot be produced by the template.
+// Templates in Mustache mode never match.
+func (t *Template) Match(s string) (map[string]string, bool) {
+	if t.mustache {
+		return nil, false
+	}
+	last := len(t.contentIntervalIdx) - 1
+	if last == 0 {
+		if s != b2s(t.content) {
+			return nil, false
+		}
+		return map[string]string{}, true
+	}
+	head := b2s(t.content[t.contentIntervalIdx[0][0]:t.contentIntervalIdx[0][1]])
+	tail := b2s(t.content[t.contentIntervalIdx[last][0]:])
+	if len(s) < len(head)+len(tail) || !strings.HasPrefix(s, head) || !strings.HasSuffix(s, tail) {
+		return nil, false
+	}
+
+	m := &matcher{t: t, s: s[:len(s)-len(tail)], values: make(map[string]string, last), spans: make(map[string]int, last), failed: map[matchState]struct{}{}}
+	m.shared = make([][]string, last)
+	for i := range m.shared {
+		for j := i; j < last; j++ {
+			k := b2s(t.args[j])
+			for _, a := range t.args[:i] {
+				if b2s(a) == k {
+					m.shared[i] = append(m.shared[i], k)
+					break
+				}
+			}
+		}
+	}
+	if !m.match(len(head), 0) {
+		return nil, false
+	}
+	return m.values, true
+}
+
+// matcher holds the state of a Match call. The trailing static content has already been cut off s.
+// Failures are memoised, so that matching takes polynomial time even when anchors occur many times:
+// quadratic in the length of s for templates whose keys are distinct, and of a higher degree for
+// templates using a key more than once.
+type matcher struct {
+	t      *Template
+	s      string
+	values map[string]string
+	// spans holds the offset in s of the first occurrence of each value.
+	spans map[string]int
+	// shared[i] are the keys of placeholders i and later that also occur before placeholder i:
+	// whether the rest of the template matches from placeholder i depends on their values.
+	shared [][]string
+	failed map[matchState]struct{}
+}
+
+// matchState identifies a failed attempt to match placeholder i at position pos of s,
+// with bound holding the offsets and lengths of the values of shared[i].
+type matchState struct {
+	i, pos int
+	bound  string
+}
+
+// state returns the state of matching placeholder i at pos.
+func (m *matcher) state(i, pos int) matchState {
+	st := matchState{i: i, pos: pos}
+	if len(m.shared[i]) > 0 {
+		b := make([]byte, 0, len(m.shared[i])*8)
+		for _, k := range m.shared[i] {
+			b = strconv.AppendInt(b, int64(m.spans[k]), 10)
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(len(m.values[k])), 10)
+			b = append(b, ',')
+		}
+		st.bound = string(b)
+	}
+	return st
+}
+
+// match matches placeholder i, starting at s[pos:], and the rest of the template against s.
+func (m *matcher) match(pos, i int) bool {
+	st := m.state(i, pos)
+	if _, ok := m.failed[st]; ok {
+		return false
+	}
+	if m.try(pos, i) {
+		return true
+	}
+	m.failed[st] = struct{}{}
+	return false
+}
+
+// try implements match, trying the values of placeholder i from the shortest.
+func (m *matcher) try(pos, i int) bool {
+	t, s, values := m.t, m.s, m.values
+	key := b2s(t.args[i])
+	last := i == len(t.contentIntervalIdx)-2
+
+	var anchor string
+	if !last {
+		anchor = b2s(t.content[t.contentIntervalIdx[i+1][0]:t.contentIntervalIdx[i+1][1]])
+	}
+	if prev, seen := values[key]; seen {
+		// the value of a key already matched is known: only that value is tried.
+		end := pos + len(prev)
+		if end > len(s) || s[pos:end] != prev {
+			return false
+		}
+		if last {
+			return end == len(s)
+		}
+		return strings.HasPrefix(s[end:], anchor) && m.match(end+len(anchor), i+1)
+	}
+	for end := pos + 1; end <= len(s); end++ {
+		if last {
+			end = len(s)
+		} else if anchor != "" {
+			k := strings.Index(s[end:], anchor)
+			if k < 0 {
+				return false
+			}
+			end += k
+		}
+
+		v := s[pos:end]
+		if !t.accept(key, v) {
+			if last {
+				return false
+			}
+			continue
+		}
+		values[key] = v
+		if last {
+			return true
+		}
+		m.spans[key] = pos
+		if m.match(end+len(anchor), i+1) {
+			return true
+		}
+		delete(values, key)
+	}
+	return false
+}
+
+// accept reports whether v satisfies the constraint of key, if any.
+func (t *Template) accept(key, v string) bool {
+	if c, ok := t.constraints[key]; ok {
+		return c(v)
+	}
+	return true
+}
+
+// Matcher selects, among many templates, the one that best matches a string, like a router selecting a route.
+// Templates are tried from the most specific to the least specific: more static content first,
+// then fewer placeholders, then more constrained placeholders, then in the order they were added.
+// A Matcher is not safe for concurrent use while templates are being added.
+type Matcher struct {
+	templates []*Template
+}
+
+// NewMatcher creates a Matcher for the provided templates.
+func NewMatcher(templates ...*Template) *Matcher {
+	m := &Matcher{}
+	for _, t := range templates {
+		m.Add(t)
+	}
+	return m
+}
+
+// Add adds a template to the matcher.
+func (m *Matcher) Add(t *Template) {
+	m.templates = append(m.templates, t)
+	sort.SliceStable(m.templates, func(i, j int) bool {
+		a, b := m.templates[i], m.templates[j]
+		if sa, sb := a.staticLen(), b.staticLen(); sa != sb {
+			return sa > sb
+		}
+		if len(a.args) != len(b.args) {
+			return len(a.args) < len(b.args)
+		}
+		return len(a.constraints) > len(b.constraints)
+	})
+}
+
+// Match returns the best template matching s together with the extracted placeholder values.
+// It reports false if no template matches.
+func (m *Matcher) Match(s string) (*Template, map[string]string, bool) {
+	for _, t := range m.templates {
+		if values, ok := t.Match(s); ok {
+			return t, values, true
+		}
+	}
+	return nil, nil, false
+}
+
+// staticLen returns the length of the static content of the template.
+func (t *Template) staticLen() int {
+	n := len(t.content)
+	for i := 0; i < len(t.contentIntervalIdx)-1; i++ {
+		n -= len(t.placeholder(i))
+	}
+	return n
+}
+
+// setConstraint sets the constraint of the placeholder key.
+func (t *Template) setConstraint(key string, c func(string) bool) {
+	if t.constraints == nil {
+		t.constraints = make(map[string]func(string) bool)
+	}
+	t.constraints[key] = c
+}
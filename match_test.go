@@ -0,0 +1,162 @@
+package easytmpl
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate_Match(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+		opts []OptionHandler
+		s    string
+		want map[string]string
+		ok   bool
+	}{
+		{
+			name: "case:url",
+			tpl:  "https://{{domain}}.com?name={{name}}&age={{age}}",
+			s:    "https://user.google.com?name=tyltr&age=18",
+			want: map[string]string{"domain": "user.google", "name": "tyltr", "age": "18"},
+			ok:   true,
+		},
+		{
+			name: "case:static content mismatch",
+			tpl:  "https://{{domain}}.com?name={{name}}",
+			s:    "http://google.com?name=tyltr",
+		},
+		{
+			name: "case:empty value",
+			tpl:  "/users/{{id}}",
+			s:    "/users/",
+		},
+		{
+			name: "case:no placeholder",
+			tpl:  "/healthz",
+			s:    "/healthz",
+			want: map[string]string{},
+			ok:   true,
+		},
+		{
+			name: "case:repeated keys",
+			tpl:  "{{a}}-{{b}}-{{a}}",
+			s:    "x-y-z-x-y",
+			want: map[string]string{"a": "x-y", "b": "z"},
+			ok:   true,
+		},
+		{
+			name: "case:repeated keys mismatch",
+			tpl:  "{{a}}/{{a}}",
+			s:    "x/y",
+		},
+		{
+			name: "case:pattern constraint",
+			tpl:  "{{name}}-{{id}}.log",
+			opts: []OptionHandler{WithPattern("id", `[0-9]+`)},
+			s:    "app-web-42.log",
+			want: map[string]string{"name": "app-web", "id": "42"},
+			ok:   true,
+		},
+		{
+			name: "case:char class constraint on adjacent placeholders",
+			tpl:  "{{word}}{{num}}",
+			opts: []OptionHandler{WithCharClass("word", "a-z"), WithCharClass("num", "0-9")},
+			s:    "abc123",
+			want: map[string]string{"word": "abc", "num": "123"},
+			ok:   true,
+		},
+		{
+			name: "case:negated char class",
+			tpl:  "/{{dir}}/{{file}}",
+			opts: []OptionHandler{WithCharClass("dir", "^/")},
+			s:    "/a/b/c",
+			want: map[string]string{"dir": "a", "file": "b/c"},
+			ok:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := NewTemplate(tt.tpl, tt.opts...)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			got, ok := template.Match(tt.s)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, %v  want:%v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestWithCharClass(t *testing.T) {
+	for _, class := range []string{"", "^", "z-a", "é"} {
+		if _, err := NewTemplate("{{a}}", WithCharClass("a", class)); !errors.Is(err, CharClassInvalidError) {
+			t.Errorf("%q: got %v  want:%v", class, err, CharClassInvalidError)
+		}
+	}
+}
+
+func TestTemplate_MatchAdversarial(t *testing.T) {
+	template, err := NewTemplate("/{{a}}/{{b}}/{{c}}/{{d}}/{{e}}/{{f}}", WithCharClass("f", "0-9"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	repeated, err := NewTemplate("/{{a}}/{{b}}/{{a}}/{{b}}/{{c}}", WithCharClass("c", "0-9"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	s := strings.Repeat("/a", 400)
+
+	start := time.Now()
+	if _, ok := template.Match(s); ok {
+		t.Errorf("got match for %d segments", 400)
+	}
+	if _, ok := repeated.Match(s); ok {
+		t.Errorf("got match for %d segments", 400)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("got %v  want:less than %v", elapsed, 2*time.Second)
+	}
+
+	got, ok := template.Match(strings.Repeat("/a", 10) + "/7")
+	want := map[string]string{"a": "a", "b": "a", "c": "a", "d": "a", "e": "a/a/a/a/a/a", "f": "7"}
+	if !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, %v  want:%v", got, ok, want)
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	var templates []*Template
+	for _, tpl := range []string{"/users/{{id}}", "/users/me", "/users/{{id}}/posts/{{post}}", "/{{page}}"} {
+		template, err := NewTemplate(tpl)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		templates = append(templates, template)
+	}
+	m := NewMatcher(templates...)
+
+	tests := []struct {
+		s    string
+		want *Template
+		args map[string]string
+	}{
+		{s: "/users/me", want: templates[1], args: map[string]string{}},
+		{s: "/users/42", want: templates[0], args: map[string]string{"id": "42"}},
+		{s: "/users/42/posts/7", want: templates[2], args: map[string]string{"id": "42", "post": "7"}},
+		{s: "/about", want: templates[3], args: map[string]string{"page": "about"}},
+	}
+	for _, tt := range tests {
+		got, args, ok := m.Match(tt.s)
+		if !ok || got != tt.want || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s: got %v, %v", tt.s, args, ok)
+		}
+	}
+	if _, _, ok := m.Match("nope"); ok {
+		t.Errorf("got match for %q", "nope")
+	}
+}
@@ -0,0 +1,468 @@
+package easytmpl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// TemplateModeError indicates that an operation is not supported by the mode the template was created in,
+	// e.g. calling Render on a template that is not in Mustache mode.
+	TemplateModeError = errors.New("operation is not supported by the template mode")
+
+	// MustacheUnclosedTagError indicates that a Mustache tag is opened but never closed.
+	MustacheUnclosedTagError = errors.New("mustache: unclosed tag")
+
+	// MustacheUnclosedSectionError indicates that a Mustache section is opened but never closed.
+	MustacheUnclosedSectionError = errors.New("mustache: unclosed section")
+
+	// MustacheUnexpectedCloseError indicates that a Mustache close tag does not match the open section.
+	MustacheUnexpectedCloseError = errors.New("mustache: unexpected close tag")
+
+	// MustacheInvalidDelimiterError indicates that a Mustache set delimiter tag is malformed.
+	MustacheInvalidDelimiterError = errors.New("mustache: invalid set delimiter tag")
+
+	// MustachePartialDepthError indicates that partials are nested deeper than mustacheMaxPartialDepth,
+	// which usually means a partial recurses into itself without a terminating section.
+	MustachePartialDepthError = errors.New("mustache: partials nested too deeply")
+)
+
+// mustacheEscaper escapes interpolated values the way the Mustache specification expects.
+var mustacheEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "<", "&lt;", ">", "&gt;")
+
+// mustacheMaxPartialDepth is the maximum nesting depth of partials during rendering.
+const mustacheMaxPartialDepth = 256
+
+type mustacheKind uint8
+
+const (
+	mustacheText mustacheKind = iota
+	mustacheVariable
+	mustacheSection
+	mustacheInverted
+	mustachePartial
+)
+
+// mustacheNode is a node of a compiled Mustache template.
+type mustacheNode struct {
+	kind     mustacheKind
+	text     []byte
+	name     string
+	escape   bool
+	indent   string
+	children []*mustacheNode
+	// offset is the offset of the tag in the template source, for diagnostics.
+	offset int
+}
+
+// parseMustache compiles src into a tree of Mustache nodes, using start and end as the initial delimiters.
+// Standalone lines (a section, inverted section, close, comment, partial or set delimiter tag that is
+// the only non-whitespace content of its line) are removed from the output as the specification requires.
+func parseMustache(src []byte, start, end []byte) ([]*mustacheNode, error) {
+	var (
+		root      = &mustacheNode{kind: mustacheSection}
+		stack     = []*mustacheNode{root}
+		textStart = 0
+		lineStart = true
+	)
+
+	for {
+		i := bytes.Index(src[textStart:], start)
+		if i < 0 {
+			break
+		}
+		tagStart := textStart + i
+		inner := tagStart + len(start)
+
+		closer := end
+		triple := inner < len(src) && src[inner] == '{'
+		if triple {
+			closer = append([]byte{'}'}, end...)
+			inner++
+		}
+		j := bytes.Index(src[inner:], closer)
+		if j < 0 {
+			return nil, newDiagnostic(MustacheUnclosedTagError, string(src), tagStart,
+				"add the closing delimiter "+strconv.Quote(string(closer)))
+		}
+		tagEnd := inner + j + len(closer)
+		body := strings.TrimSpace(string(src[inner : inner+j]))
+
+		var sigil byte
+		if triple {
+			sigil = '{'
+		} else if len(body) > 0 {
+			switch body[0] {
+			case '!', '=', '#', '^', '/', '>', '&':
+				sigil = body[0]
+				body = strings.TrimSpace(body[1:])
+			}
+		}
+
+		// text before the tag, shortened to the start of the line if the tag is standalone.
+		text := src[textStart:tagStart]
+		next, nextLineStart := tagEnd, false
+		indent := ""
+
+		switch sigil {
+		case '!', '=', '#', '^', '/', '>':
+			if ls, after, ok := mustacheStandalone(src, textStart, tagStart, tagEnd, lineStart); ok {
+				text = src[textStart:ls]
+				indent = string(src[ls:tagStart])
+				next, nextLineStart = after, true
+			}
+		}
+
+		parent := stack[len(stack)-1]
+		parent.children = appendMustacheText(parent.children, text)
+
+		switch sigil {
+		case '!':
+		case '=':
+			fields := strings.Fields(strings.TrimSuffix(body, "="))
+			if !strings.HasSuffix(body, "=") || len(fields) != 2 {
+				return nil, newDiagnostic(MustacheInvalidDelimiterError, string(src), tagStart,
+					"a set delimiter tag holds the new start and end delimiters separated by a space, e.g. {{=<% %>=}}")
+			}
+			pair, err := NewTagPair(fields[0], fields[1])
+			if err != nil {
+				return nil, err
+			}
+			start, end = pair.start, pair.end
+		case '#', '^':
+			kind := mustacheSection
+			if sigil == '^' {
+				kind = mustacheInverted
+			}
+			node := &mustacheNode{kind: kind, name: body, offset: tagStart}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case '/':
+			if len(stack) == 1 {
+				return nil, newDiagnostic(MustacheUnexpectedCloseError, string(src), tagStart, "no section is open")
+			}
+			if parent.name != body {
+				return nil, newDiagnostic(MustacheUnexpectedCloseError, string(src), tagStart,
+					"the open section is "+strconv.Quote(parent.name))
+			}
+			stack = stack[:len(stack)-1]
+		case '>':
+			parent.children = append(parent.children, &mustacheNode{kind: mustachePartial, name: body, indent: indent})
+		case '&', '{':
+			parent.children = append(parent.children, &mustacheNode{kind: mustacheVariable, name: body, offset: tagStart})
+		default:
+			parent.children = append(parent.children, &mustacheNode{kind: mustacheVariable, name: body, escape: true, offset: tagStart})
+		}
+
+		textStart, lineStart = next, nextLineStart
+	}
+
+	if len(stack) != 1 {
+		open := stack[len(stack)-1]
+		return nil, newDiagnostic(MustacheUnclosedSectionError, string(src), open.offset,
+			"close the section with a "+strconv.Quote("/"+open.name)+" tag")
+	}
+	root.children = appendMustacheText(root.children, src[textStart:])
+	return root.children, nil
+}
+
+// mustacheStandalone reports whether the tag src[tagStart:tagEnd] is the only non-whitespace content of its line.
+// If so, it returns the start of the line and the position right after the line break that ends it.
+func mustacheStandalone(src []byte, textStart, tagStart, tagEnd int, lineStart bool) (int, int, bool) {
+	ls := textStart
+	if nl := bytes.LastIndexByte(src[textStart:tagStart], '\n'); nl >= 0 {
+		ls = textStart + nl + 1
+	} else if !lineStart {
+		return 0, 0, false
+	}
+	for _, c := range src[ls:tagStart] {
+		if c != ' ' && c != '\t' {
+			return 0, 0, false
+		}
+	}
+
+	after := tagEnd
+	for after < len(src) && (src[after] == ' ' || src[after] == '\t') {
+		after++
+	}
+	switch {
+	case after == len(src):
+	case src[after] == '\n':
+		after++
+	case src[after] == '\r' && after+1 < len(src) && src[after+1] == '\n':
+		after += 2
+	default:
+		return 0, 0, false
+	}
+	return ls, after, true
+}
+
+// appendMustacheText appends a static text node to nodes, skipping empty text.
+func appendMustacheText(nodes []*mustacheNode, text []byte) []*mustacheNode {
+	if len(text) == 0 {
+		return nodes
+	}
+	return append(nodes, &mustacheNode{kind: mustacheText, text: text})
+}
+
+// Mustache reports whether the template is in Mustache mode, see WithMustache.
+func (t *Template) Mustache() bool {
+	return t.mustache
+}
+
+// Render renders a template created with WithMustache against data and writes the result to w.
+// data is usually a map[string]any, a struct or a pointer to a struct; it forms the root of the context stack.
+// It returns TemplateModeError if the template is not in Mustache mode.
+func (t *Template) Render(w io.Writer, data any) (err error) {
+	defer t.wrapError(&err)
+	if !t.mustache {
+		return TemplateModeError
+	}
+	r := t.newMustacheRenderer(w, false)
+	return r.render(t.nodes, []any{data})
+}
+
+// RenderString renders a template created with WithMustache against data and returns the result as a string.
+func (t *Template) RenderString(data any) (string, error) {
+	var bb bytes.Buffer
+	bb.Grow(max(len(t.content)*2, t.capacity))
+	if err := t.Render(&bb, data); err != nil {
+		return "", err
+	}
+	return bb.String(), nil
+}
+
+// mustacheRenderer holds the state of a single Mustache render.
+type mustacheRenderer struct {
+	t        *Template
+	w        io.Writer
+	strict   bool
+	depth    int
+	vars     int
+	partials map[string][]*mustacheNode
+}
+
+// newMustacheRenderer returns a renderer writing to w, enforcing the template's size limits.
+func (t *Template) newMustacheRenderer(w io.Writer, strict bool) *mustacheRenderer {
+	return &mustacheRenderer{t: t, w: t.newLimitWriter(w), strict: strict}
+}
+
+// render writes nodes to the renderer's writer, resolving names against the context stack.
+func (r *mustacheRenderer) render(nodes []*mustacheNode, stack []any) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case mustacheText:
+			if lw, ok := r.w.(*limitWriter); ok {
+				lw.placeholder(-1, "")
+			}
+			if _, err := r.w.Write(n.text); err != nil {
+				return err
+			}
+		case mustacheVariable:
+			v, ok := mustacheLookup(stack, n.name)
+			if !ok {
+				r.t.missingKey(n.name)
+				if r.strict {
+					if r.depth > 0 {
+						// the offset is in the source of a partial.
+						return TemplateExecMissingParameterError
+					}
+					return r.t.diagnostic(TemplateExecMissingParameterError, n.offset, "no value for "+strconv.Quote(n.name))
+				}
+				continue
+			}
+			s := mustacheString(v)
+			if n.escape {
+				s = mustacheEscaper.Replace(s)
+			}
+			if lw, ok := r.w.(*limitWriter); ok {
+				lw.placeholder(r.vars, n.name)
+			}
+			r.vars++
+			if _, err := io.WriteString(r.w, s); err != nil {
+				return err
+			}
+		case mustacheSection:
+			v, _ := mustacheLookup(stack, n.name)
+			if mustacheFalsey(v) {
+				continue
+			}
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				for i := 0; i < rv.Len(); i++ {
+					if err := r.render(n.children, append(stack, rv.Index(i).Interface())); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := r.render(n.children, append(stack, v)); err != nil {
+				return err
+			}
+		case mustacheInverted:
+			v, _ := mustacheLookup(stack, n.name)
+			if !mustacheFalsey(v) {
+				continue
+			}
+			if err := r.render(n.children, stack); err != nil {
+				return err
+			}
+		case mustachePartial:
+			nodes, err := r.partial(n.name, n.indent)
+			if err != nil {
+				return err
+			}
+			if r.depth >= mustacheMaxPartialDepth {
+				return MustachePartialDepthError
+			}
+			r.depth++
+			err = r.render(nodes, stack)
+			r.depth--
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// partial returns the compiled partial registered under name, with indent prepended to each of its lines.
+// Unknown partials render as empty. Partials are parsed with the template's initial tag pair, so set
+// delimiter tags neither leak into nor out of a partial.
+func (r *mustacheRenderer) partial(name, indent string) ([]*mustacheNode, error) {
+	key := name + "\x00" + indent
+	if nodes, ok := r.partials[key]; ok {
+		return nodes, nil
+	}
+	src, ok := r.t.partials[name]
+	if !ok {
+		return nil, nil
+	}
+	if indent != "" {
+		src = indent + strings.ReplaceAll(src, "\n", "\n"+indent)
+		src = strings.TrimSuffix(src, indent)
+	}
+	nodes, err := parseMustache([]byte(src), r.t.pairs.start, r.t.pairs.end)
+	if err != nil {
+		return nil, err
+	}
+	if r.partials == nil {
+		r.partials = make(map[string][]*mustacheNode)
+	}
+	r.partials[key] = nodes
+	return nodes, nil
+}
+
+// mustacheLookup resolves a (possibly dotted) name against the context stack.
+// The first segment is searched from the top of the stack down; the remaining segments
+// are resolved only within the value found for the first one.
+func mustacheLookup(stack []any, name string) (any, bool) {
+	if name == "." {
+		return stack[len(stack)-1], true
+	}
+	first, rest, _ := strings.Cut(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		v, ok := mustacheField(stack[i], first)
+		if !ok {
+			continue
+		}
+		for rest != "" {
+			var key string
+			key, rest, _ = strings.Cut(rest, ".")
+			if v, ok = mustacheField(v, key); !ok {
+				return nil, false
+			}
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// mustacheField returns the value stored under key in a map with string keys or in an exported struct field.
+func mustacheField(v any, key string) (any, bool) {
+	switch m := v.(type) {
+	case nil:
+		return nil, false
+	case map[string]any:
+		x, ok := m[key]
+		return x, ok
+	case map[string]string:
+		x, ok := m[key]
+		return x, ok
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		x := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key()))
+		if !x.IsValid() {
+			return nil, false
+		}
+		return x.Interface(), true
+	case reflect.Struct:
+		f, ok := rv.Type().FieldByName(key)
+		if !ok || !f.IsExported() {
+			return nil, false
+		}
+		return rv.FieldByIndex(f.Index).Interface(), true
+	}
+	return nil, false
+}
+
+// mustacheFalsey reports whether v is false, nil or an empty list.
+func mustacheFalsey(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !x
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Len() == 0
+	case reflect.Pointer, reflect.Interface, reflect.Map:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// mustacheString formats an interpolated value.
+func mustacheString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case bool:
+		return strconv.FormatBool(x)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,129 @@
+package easytmpl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mustacheSpec is the layout of a Mustache specification file.
+type mustacheSpec struct {
+	Tests []struct {
+		Name     string            `yaml:"name"`
+		Data     any               `yaml:"data"`
+		Template string            `yaml:"template"`
+		Partials map[string]string `yaml:"partials"`
+		Expected string            `yaml:"expected"`
+	} `yaml:"tests"`
+}
+
+func TestTemplate_MustacheSpec(t *testing.T) {
+	files, err := filepath.Glob("testdata/mustache/*.yml")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no spec files found: %v", err)
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		var spec mustacheSpec
+		if err := yaml.Unmarshal(b, &spec); err != nil {
+			t.Fatalf("unmarshal %s: %v", file, err)
+		}
+		for _, tt := range spec.Tests {
+			t.Run(filepath.Base(file)+"/"+tt.Name, func(t *testing.T) {
+				template, err := NewTemplate(tt.Template, WithMustache(), WithPartials(tt.Partials))
+				if err != nil {
+					t.Fatalf("error %v", err)
+				}
+				got, err := template.RenderString(tt.Data)
+				if err != nil {
+					t.Fatalf("error %v", err)
+				}
+				if got != tt.Expected {
+					t.Errorf("got %q  want:%q", got, tt.Expected)
+				}
+			})
+		}
+	}
+}
+
+func TestTemplate_Mustache(t *testing.T) {
+	t.Run("case:user-defined tag pair `[[` & `]]` as initial delimiters", func(t *testing.T) {
+		template, err := NewTemplate("[[#items]]<[[name]]>[[/items]]", WithTagPair("[[", "]]"), WithMustache())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.RenderString(map[string]any{
+			"items": []map[string]string{{"name": "a"}, {"name": "b"}},
+		})
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "<a><b>"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:struct data", func(t *testing.T) {
+		type user struct {
+			Name  string
+			Admin bool
+		}
+		template, err := NewTemplate("{{Name}}{{#Admin}} (admin){{/Admin}}", WithMustache())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.RenderString(&user{Name: "tyltr", Admin: true})
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "tyltr (admin)"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:ExecString with strict mode", func(t *testing.T) {
+		template, err := NewTemplate("i am {{name}}, from {{country}}", WithMustache())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecString(map[string]string{"name": "tyltr"}, false)
+		if err != nil || got != "i am tyltr, from " {
+			t.Errorf("got %q, %v", got, err)
+		}
+		_, err = template.ExecString(map[string]string{"name": "tyltr"}, true)
+		if !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Errorf("got %v  want:%v", err, TemplateExecMissingParameterError)
+		}
+	})
+
+	t.Run("case:parse errors", func(t *testing.T) {
+		tests := map[string]error{
+			"{{#a}}":        MustacheUnclosedSectionError,
+			"{{#a}}{{/b}}":  MustacheUnexpectedCloseError,
+			"{{a":           MustacheUnclosedTagError,
+			"{{=<% %>}}":    MustacheInvalidDelimiterError,
+			"{{=<% %> |=}}": MustacheInvalidDelimiterError,
+		}
+		for tpl, want := range tests {
+			if _, err := NewTemplate(tpl, WithMustache()); !errors.Is(err, want) {
+				t.Errorf("%s: got %v  want:%v", tpl, err, want)
+			}
+		}
+	})
+
+	t.Run("case:mode mismatch", func(t *testing.T) {
+		template, err := NewTemplate("{{name}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if _, err := template.RenderString(nil); !errors.Is(err, TemplateModeError) {
+			t.Errorf("got %v  want:%v", err, TemplateModeError)
+		}
+	})
+}
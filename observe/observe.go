@@ -0,0 +1,113 @@
+// Package observe provides easytmpl.Hooks that record render events as log/slog records and expvar counters.
+package observe
+
+import (
+	"context"
+	"expvar"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+// Join returns hooks that call each of hooks in order.
+func Join(hooks ...easytmpl.Hooks) easytmpl.Hooks {
+	return easytmpl.Hooks{
+		OnRenderStart: func(t *easytmpl.Template) {
+			for _, h := range hooks {
+				if h.OnRenderStart != nil {
+					h.OnRenderStart(t)
+				}
+			}
+		},
+		OnRenderEnd: func(t *easytmpl.Template, size int, elapsed time.Duration, err error) {
+			for _, h := range hooks {
+				if h.OnRenderEnd != nil {
+					h.OnRenderEnd(t, size, elapsed, err)
+				}
+			}
+		},
+		OnMissingKey: func(t *easytmpl.Template, key string) {
+			for _, h := range hooks {
+				if h.OnMissingKey != nil {
+					h.OnMissingKey(t, key)
+				}
+			}
+		},
+		OnError: func(t *easytmpl.Template, err error) {
+			for _, h := range hooks {
+				if h.OnError != nil {
+					h.OnError(t, err)
+				}
+			}
+		},
+	}
+}
+
+// SlogHooks returns hooks that log render events of the template called name to logger:
+// the end of each render at debug level, missing keys at warn level and errors at error level.
+func SlogHooks(logger *slog.Logger, name string) easytmpl.Hooks {
+	return easytmpl.Hooks{
+		OnRenderEnd: func(t *easytmpl.Template, size int, elapsed time.Duration, err error) {
+			logger.LogAttrs(context.Background(), slog.LevelDebug, "easytmpl render",
+				slog.String("template", name),
+				slog.Int("size", size),
+				slog.Duration("elapsed", elapsed),
+				slog.Bool("ok", err == nil),
+			)
+		},
+		OnMissingKey: func(t *easytmpl.Template, key string) {
+			logger.LogAttrs(context.Background(), slog.LevelWarn, "easytmpl missing key",
+				slog.String("template", name),
+				slog.String("key", key),
+			)
+		},
+		OnError: func(t *easytmpl.Template, err error) {
+			logger.LogAttrs(context.Background(), slog.LevelError, "easytmpl render failed",
+				slog.String("template", name),
+				slog.String("error", err.Error()),
+			)
+		},
+	}
+}
+
+var (
+	publishOnce sync.Once
+	templates   *expvar.Map
+	mu          sync.Mutex
+)
+
+// Vars returns the expvar map published as "easytmpl". It holds one map per template name with the counters
+// "renders", "errors", "missing_keys", "bytes" and "nanoseconds".
+func Vars() *expvar.Map {
+	publishOnce.Do(func() {
+		templates = expvar.NewMap("easytmpl")
+	})
+	return templates
+}
+
+// ExpvarHooks returns hooks that count render events of the template called name in Vars.
+func ExpvarHooks(name string) easytmpl.Hooks {
+	mu.Lock()
+	m, ok := Vars().Get(name).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map).Init()
+		Vars().Set(name, m)
+	}
+	mu.Unlock()
+
+	return easytmpl.Hooks{
+		OnRenderEnd: func(t *easytmpl.Template, size int, elapsed time.Duration, err error) {
+			m.Add("renders", 1)
+			m.Add("bytes", int64(size))
+			m.Add("nanoseconds", int64(elapsed))
+		},
+		OnMissingKey: func(t *easytmpl.Template, key string) {
+			m.Add("missing_keys", 1)
+		},
+		OnError: func(t *easytmpl.Template, err error) {
+			m.Add("errors", 1)
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package observe
+
+import (
+	"bytes"
+	"expvar"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+func TestSlogHooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	template, err := easytmpl.NewTemplate("{{a}} {{b}}", easytmpl.WithHooks(SlogHooks(logger, "greeting")))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := template.ExecString(map[string]string{"a": "1"}, true); err == nil {
+		t.Fatalf("got nil error")
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`level=WARN msg="easytmpl missing key" template=greeting key=b`,
+		`level=DEBUG msg="easytmpl render" template=greeting size=0`,
+		`level=ERROR msg="easytmpl render failed" template=greeting error="missing parameter"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("got %q\nwant:%q", got, want)
+		}
+	}
+}
+
+func TestExpvarHooks(t *testing.T) {
+	template, err := easytmpl.NewTemplate("{{a}} {{b}}", easytmpl.WithHooks(Join(ExpvarHooks("counter"), ExpvarHooks("counter"))))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if _, err := template.ExecString(map[string]string{"a": "1"}, false); err != nil {
+		t.Fatalf("error %v", err)
+	}
+
+	m, ok := expvar.Get("easytmpl").(*expvar.Map).Get("counter").(*expvar.Map)
+	if !ok {
+		t.Fatalf("counters of %q are not published", "counter")
+	}
+	for key, want := range map[string]string{"renders": "2", "missing_keys": "2", "bytes": "14"} {
+		if got := m.Get(key).String(); got != want {
+			t.Errorf("%s: got %v  want:%v", key, got, want)
+		}
+	}
+	if m.Get("errors") != nil {
+		t.Errorf("got errors %v", m.Get("errors"))
+	}
+}
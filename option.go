@@ -40,3 +40,176 @@ func WithAutoFill(s string) OptionHandler {
 		return nil
 	}
 }
+
+// WithFormatSpecs enables format specs: the text after the first `:` of a placeholder is its format spec,
+// e.g. `{{price:%.2f}}` or `{{ts:2006-01-02}}` have the keys price and ts. See ExecValues.
+// Without it, a `:` is part of the key, e.g. `{{host:port}}` has the key host:port.
+func WithFormatSpecs() OptionHandler {
+	return func(t *Template) error {
+		t.formatSpecs = true
+		return nil
+	}
+}
+
+// WithListSpecs enables list specs: a placeholder followed by `,`, e.g. `{{tags,}}` or `{{tags, }}`, joins the
+// elements of its value with the text after the comma, and a placeholder starting with `?`, e.g. `{{?q}}`,
+// expands its value into a URL-encoded query string. See ExecLists.
+// Without it, `,` and `?` are part of the key, e.g. `{{a,b}}` has the key a,b.
+func WithListSpecs() OptionHandler {
+	return func(t *Template) error {
+		t.listSpecs = true
+		return nil
+	}
+}
+
+// WithMustache enables Mustache mode, in which the template supports variables, sections, inverted sections,
+// comments, partials and set delimiter tags as described by the Mustache specification.
+// The tag pair set by WithTagPair is used as the initial delimiters.
+// Templates in Mustache mode are rendered with Render, RenderString or ExecString.
+func WithMustache() OptionHandler {
+	return func(t *Template) error {
+		t.mustache = true
+		return nil
+	}
+}
+
+// WithPartials registers the partial templates available to `{{> name}}` tags in Mustache mode.
+func WithPartials(partials map[string]string) OptionHandler {
+	return func(t *Template) error {
+		t.partials = partials
+		return nil
+	}
+}
+
+// WithSyntax sets the syntax used to recognise placeholders in the template. The default is TagSyntax.
+func WithSyntax(s Syntax) OptionHandler {
+	return func(t *Template) error {
+		if s > ShellSyntax {
+			return errors.New("invalid template syntax")
+		}
+		t.syntax = s
+		return nil
+	}
+}
+
+// WithPattern constrains the values that Match accepts for the placeholder key to those fully matched
+// by the regular expression pattern.
+func WithPattern(key, pattern string) OptionHandler {
+	return func(t *Template) error {
+		c, err := newPatternConstraint(pattern)
+		if err != nil {
+			return err
+		}
+		t.setConstraint(key, c)
+		return nil
+	}
+}
+
+// WithCharClass constrains the values that Match accepts for the placeholder key to those consisting only of
+// characters in class. class uses the syntax of a bracket expression without the brackets, e.g. `a-z0-9_` or `^/`.
+func WithCharClass(key, class string) OptionHandler {
+	return func(t *Template) error {
+		c, err := newCharClassConstraint(class)
+		if err != nil {
+			return err
+		}
+		t.setConstraint(key, c)
+		return nil
+	}
+}
+
+// WithMaxTemplateSize limits the size of the template source accepted by NewTemplate to n bytes.
+func WithMaxTemplateSize(n int) OptionHandler {
+	return func(t *Template) error {
+		if n <= 0 {
+			return errors.New("invalid max template size")
+		}
+		t.maxTemplateSize = n
+		return nil
+	}
+}
+
+// WithMaxOutputSize limits the size of a rendered output to n bytes.
+// Rendering stops with a LimitExceededError as soon as a write would cross the limit.
+func WithMaxOutputSize(n int) OptionHandler {
+	return func(t *Template) error {
+		if n <= 0 {
+			return errors.New("invalid max output size")
+		}
+		t.maxOutputSize = n
+		return nil
+	}
+}
+
+// WithMaxValueSize limits the size of the value written for a single placeholder to n bytes.
+// Rendering stops with a LimitExceededError naming the placeholder whose value crosses the limit.
+func WithMaxValueSize(n int) OptionHandler {
+	return func(t *Template) error {
+		if n <= 0 {
+			return errors.New("invalid max value size")
+		}
+		t.maxValueSize = n
+		return nil
+	}
+}
+
+// WithSecretKeys marks the placeholders keys as secret, in addition to those starting with SecretKeyPrefix.
+// Values of secret placeholders are redacted in errors, source maps, hooks and debug output.
+func WithSecretKeys(keys ...string) OptionHandler {
+	return func(t *Template) error {
+		if t.secrets == nil {
+			t.secrets = make(map[string]struct{}, len(keys))
+		}
+		for _, k := range keys {
+			t.secrets[k] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithHooks sets hooks that observe the rendering of the template by ExecString and ExecuteFunc.
+// Templates without hooks pay no overhead.
+func WithHooks(h Hooks) OptionHandler {
+	return func(t *Template) error {
+		t.hooks = &h
+		return nil
+	}
+}
+
+// WithName sets the name of the template. Errors returned by a named template are wrapped in a TemplateError.
+func WithName(name string) OptionHandler {
+	return func(t *Template) error {
+		t.name = name
+		return nil
+	}
+}
+
+// WithVersion sets the version of the template, e.g. a release tag or a revision.
+// Errors returned by a versioned template are wrapped in a TemplateError.
+func WithVersion(version string) OptionHandler {
+	return func(t *Template) error {
+		t.version = version
+		return nil
+	}
+}
+
+// WithIndent renders multi-line values with the indentation of their placeholder: each line of the value
+// but the first is prefixed with the leading whitespace of the line containing the placeholder, so that
+// values inserted in YAML or nested code stay valid. Without keys, it applies to all placeholders;
+// otherwise only to the placeholders with those keys. The indentation counts towards WithMaxValueSize.
+// It has no effect in Mustache mode.
+func WithIndent(keys ...string) OptionHandler {
+	return func(t *Template) error {
+		if len(keys) == 0 {
+			t.indentAll = true
+			return nil
+		}
+		if t.indentKeys == nil {
+			t.indentKeys = make(map[string]struct{}, len(keys))
+		}
+		for _, k := range keys {
+			t.indentKeys[k] = struct{}{}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,116 @@
+package easytmpl
+
+import (
+	"io"
+	"strconv"
+)
+
+// PositionalArgsError is returned when a template references a positional placeholder, e.g. `{{2}}`,
+// beyond the values supplied to ExecArgs or AppendArgs.
+// It satisfies errors.Is against TemplateExecMissingParameterError.
+type PositionalArgsError struct {
+	// Highest is the highest index referenced by the template.
+	Highest int
+	// Supplied is the number of values supplied.
+	Supplied int
+}
+
+// Error implements the error interface.
+func (e *PositionalArgsError) Error() string {
+	return "missing parameter: template references index " + strconv.Itoa(e.Highest) +
+		" but " + strconv.Itoa(e.Supplied) + " values were supplied"
+}
+
+// Unwrap returns TemplateExecMissingParameterError.
+func (e *PositionalArgsError) Unwrap() error {
+	return TemplateExecMissingParameterError
+}
+
+// parsePositions records the index of each numeric placeholder, e.g. `{{0}}`, in t.positions (-1 for named ones)
+// and one more than the highest index in t.positional. t.positions is left nil if there are no numeric placeholders.
+func (t *Template) parsePositions() {
+	for i, a := range t.args {
+		n, ok := placeholderIndex(a)
+		if !ok {
+			continue
+		}
+		if t.positions == nil {
+			t.positions = make([]int, len(t.args))
+			for j := range t.positions {
+				t.positions[j] = -1
+			}
+		}
+		t.positions[i] = n
+		t.positional = max(t.positional, n+1)
+	}
+}
+
+// placeholderIndex returns the index of a numeric placeholder key.
+func placeholderIndex(key []byte) (int, bool) {
+	if len(key) == 0 || len(key) > 9 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range key {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// appendWriter is an io.Writer that appends to a byte slice.
+type appendWriter struct {
+	b []byte
+}
+
+// Write implements the io.Writer interface.
+func (w *appendWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+// ExecArgs renders the template with positional values: `{{0}}` is replaced by values[0], `{{1}}` by values[1],
+// and so on. It returns a PositionalArgsError if the template references an index beyond the supplied values.
+// Named placeholders are left unchanged, or replaced by the auto-fill value if one is set.
+func (t *Template) ExecArgs(values ...string) (string, error) {
+	b, err := t.AppendArgs(make([]byte, 0, max(len(t.content)*2, t.capacity)), values...)
+	if err != nil {
+		return "", err
+	}
+	return b2s(b), nil
+}
+
+// AppendArgs is like ExecArgs but appends the rendered template to dst and returns the extended buffer.
+func (t *Template) AppendArgs(dst []byte, values ...string) (_ []byte, err error) {
+	defer t.wrapError(&err)
+	if t.mustache || t.syntax != TagSyntax {
+		return dst, TemplateModeError
+	}
+	if t.positional > len(values) {
+		return dst, &PositionalArgsError{Highest: t.positional - 1, Supplied: len(values)}
+	}
+
+	w := &appendWriter{b: dst}
+	err = t.exec(w, func(w io.Writer, i int) error {
+		var err error
+		switch {
+		case t.positions != nil && t.positions[i] >= 0:
+			v := values[t.positions[i]]
+			if t.formats != nil || t.lists != nil {
+				_, err = w.Write(t.appendArg(nil, i, v))
+			} else {
+				_, err = w.Write(s2b(v))
+			}
+		case t.autoFill != nil:
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(*t.autoFill)
+		default:
+			t.missingKey(b2s(t.args[i]))
+			_, err = w.Write(t.placeholder(i))
+		}
+		return err
+	})
+	return w.b, err
+}
@@ -0,0 +1,65 @@
+package easytmpl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_parsePositions(t *testing.T) {
+	template, err := NewTemplate("{{1}} {{name}} {{0:%5s}} {{1}} {{01x}}", WithFormatSpecs())
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := []int{1, -1, 0, 1, -1}; !reflect.DeepEqual(template.positions, want) {
+		t.Errorf("got %v  want:%v", template.positions, want)
+	}
+	if template.positional != 2 {
+		t.Errorf("got %v  want:%v", template.positional, 2)
+	}
+}
+
+func TestTemplate_ExecArgs(t *testing.T) {
+	t.Run("case:positional placeholders", func(t *testing.T) {
+		template, err := NewTemplate("{{0}} failed after {{1}} retries, {{0}} aborted")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.ExecArgs("upload", "3")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "upload failed after 3 retries, upload aborted"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:mixed with named placeholders", func(t *testing.T) {
+		template, err := NewTemplate("[{{0:%-4s}}] {{name}}", WithFormatSpecs())
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, err := template.AppendArgs([]byte("> "), "a", "unused")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "> [a   ] {{name}}"; string(got) != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:too few values", func(t *testing.T) {
+		template, err := NewTemplate("{{0}} {{3}}")
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecArgs("a", "b")
+		var pe *PositionalArgsError
+		if !errors.As(err, &pe) || pe.Highest != 3 || pe.Supplied != 2 {
+			t.Fatalf("got %v", err)
+		}
+		if !errors.Is(err, TemplateExecMissingParameterError) {
+			t.Errorf("%v is not %v", err, TemplateExecMissingParameterError)
+		}
+	})
+}
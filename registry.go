@@ -0,0 +1,463 @@
+package easytmpl
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// RegistryNotFoundError indicates that no template is published under the namespace and name.
+	RegistryNotFoundError = errors.New("registry: template not found")
+
+	// RegistryNoHistoryError indicates that there is no earlier version to roll back to.
+	RegistryNoHistoryError = errors.New("registry: no earlier version")
+
+	// RegistryInvalidNameError indicates that a namespace or name is empty or cannot be used as a file name.
+	RegistryInvalidNameError = errors.New("registry: invalid namespace or name")
+
+	// RegistrySecretError indicates that a template holding secret values bound with Bind cannot be persisted.
+	RegistrySecretError = errors.New("registry: template holds bound secret values")
+
+	// RegistryChecksumError indicates that a loaded template does not match the checksum saved with it.
+	RegistryChecksumError = errors.New("registry: checksum mismatch")
+)
+
+// DefaultRegistryHistory is the number of versions kept for each template when NewRegistry is given a non-positive history.
+const DefaultRegistryHistory = 10
+
+// registryKey identifies a template in a Registry.
+type registryKey struct {
+	namespace, name string
+}
+
+// registryEntry is the immutable version history of a template, oldest first; the last version is the current one.
+type registryEntry struct {
+	versions []*Template
+}
+
+// Registry stores compiled templates under a namespace and a name, keeping a bounded history of the
+// versions published for each of them.
+//
+// Reads never take a lock: Get loads an immutable snapshot, so it can be called concurrently with
+// ExecString and with writers. Writers (Publish, Rollback, RollbackTo, Delete and Load) are serialized and
+// replace the snapshot atomically, which copies the snapshot's index; they are meant to be rare compared to reads.
+type Registry struct {
+	mu      sync.Mutex
+	entries atomic.Pointer[map[registryKey]*registryEntry]
+	history int
+}
+
+// NewRegistry creates an empty Registry keeping up to history versions of each template,
+// or DefaultRegistryHistory versions if history <= 0.
+func NewRegistry(history int) *Registry {
+	if history <= 0 {
+		history = DefaultRegistryHistory
+	}
+	r := &Registry{history: history}
+	r.entries.Store(&map[registryKey]*registryEntry{})
+	return r
+}
+
+// checkRegistryName checks that s can be used as a namespace or a name, which are also directory and file names
+// when the registry is saved.
+func checkRegistryName(s string) error {
+	if s == "" || s == "." || s == ".." || strings.ContainsAny(s, `/\`+"\x00") {
+		return RegistryInvalidNameError
+	}
+	return nil
+}
+
+// update replaces the entry of key with the result of f, under the writers' lock.
+// f receives the current entry, or nil; a nil result deletes the entry.
+func (r *Registry) update(key registryKey, f func(e *registryEntry) (*registryEntry, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := *r.entries.Load()
+	e, err := f(old[key])
+	if err != nil {
+		return err
+	}
+	entries := make(map[registryKey]*registryEntry, len(old)+1)
+	for k, v := range old {
+		entries[k] = v
+	}
+	if e == nil {
+		delete(entries, key)
+	} else {
+		entries[key] = e
+	}
+	r.entries.Store(&entries)
+	return nil
+}
+
+// Publish makes t the current version of the template namespace/name. The oldest version is dropped
+// once the history is full.
+func (r *Registry) Publish(namespace, name string, t *Template) error {
+	if err := checkRegistryName(namespace); err != nil {
+		return err
+	}
+	if err := checkRegistryName(name); err != nil {
+		return err
+	}
+	return r.update(registryKey{namespace, name}, func(e *registryEntry) (*registryEntry, error) {
+		var versions []*Template
+		if e != nil {
+			versions = e.versions[max(len(e.versions)-r.history+1, 0):]
+		}
+		return &registryEntry{versions: append(versions[:len(versions):len(versions)], t)}, nil
+	})
+}
+
+// Get returns the current version of the template namespace/name.
+func (r *Registry) Get(namespace, name string) (*Template, bool) {
+	e, ok := (*r.entries.Load())[registryKey{namespace, name}]
+	if !ok {
+		return nil, false
+	}
+	return e.versions[len(e.versions)-1], true
+}
+
+// History returns the versions of the template namespace/name, newest first.
+func (r *Registry) History(namespace, name string) []*Template {
+	e, ok := (*r.entries.Load())[registryKey{namespace, name}]
+	if !ok {
+		return nil
+	}
+	versions := make([]*Template, len(e.versions))
+	for i, t := range e.versions {
+		versions[len(versions)-1-i] = t
+	}
+	return versions
+}
+
+// Rollback drops the current version of the template namespace/name, making the previous one current again.
+// It returns RegistryNoHistoryError if there is no previous version.
+func (r *Registry) Rollback(namespace, name string) error {
+	return r.update(registryKey{namespace, name}, func(e *registryEntry) (*registryEntry, error) {
+		if e == nil {
+			return nil, RegistryNotFoundError
+		}
+		if len(e.versions) < 2 {
+			return nil, RegistryNoHistoryError
+		}
+		return &registryEntry{versions: e.versions[: len(e.versions)-1 : len(e.versions)-1]}, nil
+	})
+}
+
+// RollbackTo drops the versions of the template namespace/name published after the newest one whose
+// Version is version. It returns RegistryNoHistoryError if no such version is in the history.
+func (r *Registry) RollbackTo(namespace, name, version string) error {
+	return r.update(registryKey{namespace, name}, func(e *registryEntry) (*registryEntry, error) {
+		if e == nil {
+			return nil, RegistryNotFoundError
+		}
+		for i := len(e.versions) - 1; i >= 0; i-- {
+			if e.versions[i].Version() == version {
+				return &registryEntry{versions: e.versions[: i+1 : i+1]}, nil
+			}
+		}
+		return nil, RegistryNoHistoryError
+	})
+}
+
+// Delete removes the template namespace/name and its history.
+func (r *Registry) Delete(namespace, name string) error {
+	return r.update(registryKey{namespace, name}, func(e *registryEntry) (*registryEntry, error) {
+		if e == nil {
+			return nil, RegistryNotFoundError
+		}
+		return nil, nil
+	})
+}
+
+// Namespaces returns the namespaces holding templates, sorted.
+func (r *Registry) Namespaces() []string {
+	seen := make(map[string]struct{})
+	for k := range *r.entries.Load() {
+		seen[k.namespace] = struct{}{}
+	}
+	namespaces := make([]string, 0, len(seen))
+	for ns := range seen {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Names returns the names of the templates in namespace, sorted.
+func (r *Registry) Names(namespace string) []string {
+	var names []string
+	for k := range *r.entries.Load() {
+		if k.namespace == namespace {
+			names = append(names, k.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateConfig is the persisted form of a template: its content and the options that can be restored.
+// Constraints set with WithPattern or WithCharClass and hooks are functions; they are not persisted.
+type templateConfig struct {
+	Name            string            `json:"name,omitempty"`
+	Version         string            `json:"version,omitempty"`
+	Checksum        string            `json:"checksum"`
+	Content         string            `json:"content"`
+	Start           string            `json:"start"`
+	End             string            `json:"end"`
+	AutoFill        *string           `json:"auto_fill,omitempty"`
+	Capacity        int               `json:"capacity,omitempty"`
+	Mustache        bool              `json:"mustache,omitempty"`
+	Partials        map[string]string `json:"partials,omitempty"`
+	Syntax          Syntax            `json:"syntax,omitempty"`
+	FormatSpecs     bool              `json:"format_specs,omitempty"`
+	ListSpecs       bool              `json:"list_specs,omitempty"`
+	SecretKeys      []string          `json:"secret_keys,omitempty"`
+	Indent          bool              `json:"indent,omitempty"`
+	IndentKeys      []string          `json:"indent_keys,omitempty"`
+	MaxTemplateSize int               `json:"max_template_size,omitempty"`
+	MaxOutputSize   int               `json:"max_output_size,omitempty"`
+	MaxValueSize    int               `json:"max_value_size,omitempty"`
+	// Parsed is the binary form of a template whose content does not parse to its placeholders,
+	// e.g. a template returned by Bind with values looking like placeholders; it is restored from Parsed.
+	Parsed []byte `json:"parsed,omitempty"`
+}
+
+// configOf returns the persisted form of t.
+func configOf(t *Template) templateConfig {
+	c := templateConfig{
+		Name:            t.name,
+		Version:         t.version,
+		Checksum:        t.checksum,
+		Content:         string(t.sourceText()),
+		Start:           string(t.pairs.start),
+		End:             string(t.pairs.end),
+		Capacity:        t.capacity,
+		Mustache:        t.mustache,
+		Partials:        t.partials,
+		Syntax:          t.syntax,
+		FormatSpecs:     t.formatSpecs,
+		ListSpecs:       t.listSpecs,
+		Indent:          t.indentAll,
+		MaxTemplateSize: t.maxTemplateSize,
+		MaxOutputSize:   t.maxOutputSize,
+		MaxValueSize:    t.maxValueSize,
+	}
+	if t.autoFill != nil {
+		s := string(*t.autoFill)
+		c.AutoFill = &s
+	}
+	for k := range t.secrets {
+		c.SecretKeys = append(c.SecretKeys, k)
+	}
+	sort.Strings(c.SecretKeys)
+	for k := range t.indentKeys {
+		c.IndentKeys = append(c.IndentKeys, k)
+	}
+	sort.Strings(c.IndentKeys)
+	return c
+}
+
+// options returns the options that restore the persisted configuration, followed by opts.
+func (c templateConfig) options(opts []OptionHandler) []OptionHandler {
+	o := []OptionHandler{WithTagPair(c.Start, c.End), WithName(c.Name), WithVersion(c.Version), WithSyntax(c.Syntax)}
+	if c.AutoFill != nil {
+		o = append(o, WithAutoFill(*c.AutoFill))
+	}
+	if c.Capacity > 0 {
+		o = append(o, WithPreAllocateMemory(c.Capacity))
+	}
+	if c.Mustache {
+		o = append(o, WithMustache(), WithPartials(c.Partials))
+	}
+	if c.FormatSpecs {
+		o = append(o, WithFormatSpecs())
+	}
+	if c.ListSpecs {
+		o = append(o, WithListSpecs())
+	}
+	if len(c.SecretKeys) > 0 {
+		o = append(o, WithSecretKeys(c.SecretKeys...))
+	}
+	if c.Indent {
+		o = append(o, WithIndent())
+	}
+	if len(c.IndentKeys) > 0 {
+		o = append(o, WithIndent(c.IndentKeys...))
+	}
+	if c.MaxTemplateSize > 0 {
+		o = append(o, WithMaxTemplateSize(c.MaxTemplateSize))
+	}
+	if c.MaxOutputSize > 0 {
+		o = append(o, WithMaxOutputSize(c.MaxOutputSize))
+	}
+	if c.MaxValueSize > 0 {
+		o = append(o, WithMaxValueSize(c.MaxValueSize))
+	}
+	return append(o, opts...)
+}
+
+// template restores the persisted template, applying opts after the persisted options.
+// A template saved with its parsed form is decoded rather than parsed; opts are then applied to it
+// after decoding, so options affecting parsing, such as WithTagPair, have no effect.
+func (c templateConfig) template(opts []OptionHandler) (*Template, error) {
+	if c.Parsed == nil {
+		return NewTemplate(c.Content, c.options(opts)...)
+	}
+	t := &Template{}
+	if err := t.UnmarshalBinary(c.Parsed); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Save writes the registry to dir, one file `<namespace>/<name>.etpl.json` holding the history of each template.
+// Each file is written to a temporary file first and renamed, so a concurrent Load never reads a partial file.
+// Files of templates no longer in the registry, e.g. deleted with Delete, are removed; other files in dir,
+// including JSON files without the `.etpl.json` extension, are left alone.
+// It returns RegistrySecretError if a template holds secret values bound with Bind.
+func (r *Registry) Save(dir string) error {
+	entries := *r.entries.Load()
+	for k, e := range entries {
+		configs := make([]templateConfig, len(e.versions))
+		for i, t := range e.versions {
+			if len(t.redactions) > 0 {
+				return RegistrySecretError
+			}
+			configs[i] = configOf(t)
+			if !t.reparses() {
+				parsed, err := t.MarshalBinary()
+				if err != nil {
+					return err
+				}
+				configs[i].Parsed = parsed
+			}
+		}
+		b, err := json.MarshalIndent(configs, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(filepath.Join(dir, k.namespace, k.name+registryFileExt), b); err != nil {
+			return err
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*"+registryFileExt))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if _, ok := entries[fileKey(file)]; ok {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// the namespace directory is removed once its last template is gone.
+		os.Remove(filepath.Dir(file))
+	}
+	return nil
+}
+
+// registryFileExt is the extension of the files written by Save.
+const registryFileExt = ".etpl.json"
+
+// fileKey returns the key of the template saved in file.
+func fileKey(file string) registryKey {
+	return registryKey{filepath.Base(filepath.Dir(file)), strings.TrimSuffix(filepath.Base(file), registryFileExt)}
+}
+
+// reparses reports whether parsing the source of t again yields the same placeholders,
+// which is not the case for a template returned by Bind with values looking like placeholders.
+func (t *Template) reparses() bool {
+	if t.mustache || t.syntax != TagSyntax {
+		return true
+	}
+	nt, err := t.recompile(t.sourceText())
+	return err == nil && reflect.DeepEqual(nt.contentIntervalIdx, t.contentIntervalIdx) &&
+		reflect.DeepEqual(nt.args, t.args) && reflect.DeepEqual(nt.formats, t.formats) &&
+		reflect.DeepEqual(nt.lists, t.lists) && reflect.DeepEqual(nt.positions, t.positions)
+}
+
+// writeFileAtomic writes b to a temporary file next to path and renames it to path.
+func writeFileAtomic(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Load reads the templates saved in dir with Save and publishes them with their history, replacing the
+// templates of the same namespace and name. opts are applied to every template after the persisted options,
+// e.g. to set hooks or constraints again. Nothing is published if a template fails to load or does not
+// match its saved checksum.
+func (r *Registry) Load(dir string, opts ...OptionHandler) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*"+registryFileExt))
+	if err != nil {
+		return err
+	}
+	loaded := make(map[registryKey]*registryEntry, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var configs []templateConfig
+		if err := json.Unmarshal(b, &configs); err != nil {
+			return err
+		}
+		if len(configs) == 0 {
+			continue
+		}
+		e := &registryEntry{versions: make([]*Template, 0, min(len(configs), r.history))}
+		for _, c := range configs[max(len(configs)-r.history, 0):] {
+			t, err := c.template(opts)
+			if err != nil {
+				return err
+			}
+			if t.Checksum() != c.Checksum {
+				return RegistryChecksumError
+			}
+			e.versions = append(e.versions, t)
+		}
+		loaded[fileKey(file)] = e
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := *r.entries.Load()
+	entries := make(map[registryKey]*registryEntry, len(old)+len(loaded))
+	for k, v := range old {
+		entries[k] = v
+	}
+	for k, v := range loaded {
+		entries[k] = v
+	}
+	r.entries.Store(&entries)
+	return nil
+}
@@ -0,0 +1,204 @@
+package easytmpl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	publish := func(t *testing.T, r *Registry, version, tpl string) {
+		t.Helper()
+		template, err := NewTemplate(tpl, WithName("welcome"), WithVersion(version))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if err := r.Publish("billing", "welcome", template); err != nil {
+			t.Fatalf("error %v", err)
+		}
+	}
+	render := func(t *testing.T, r *Registry) string {
+		t.Helper()
+		template, ok := r.Get("billing", "welcome")
+		if !ok {
+			t.Fatalf("template not found")
+		}
+		s, err := template.ExecString(map[string]string{"name": "Ada"}, true)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		return s
+	}
+
+	t.Run("case:publish and rollback", func(t *testing.T) {
+		r := NewRegistry(3)
+		for i := 1; i <= 4; i++ {
+			publish(t, r, "v"+strconv.Itoa(i), "hello {{name}} "+strconv.Itoa(i))
+		}
+		if got, want := render(t, r), "hello Ada 4"; got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+		if got := len(r.History("billing", "welcome")); got != 3 {
+			t.Errorf("got %v  want:%v", got, 3)
+		}
+
+		if err := r.Rollback("billing", "welcome"); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, want := render(t, r), "hello Ada 3"; got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+		if err := r.RollbackTo("billing", "welcome", "v1"); !errors.Is(err, RegistryNoHistoryError) {
+			t.Errorf("got %v  want:%v", err, RegistryNoHistoryError)
+		}
+		if err := r.RollbackTo("billing", "welcome", "v2"); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if err := r.Rollback("billing", "welcome"); !errors.Is(err, RegistryNoHistoryError) {
+			t.Errorf("got %v  want:%v", err, RegistryNoHistoryError)
+		}
+		if err := r.Rollback("billing", "missing"); !errors.Is(err, RegistryNotFoundError) {
+			t.Errorf("got %v  want:%v", err, RegistryNotFoundError)
+		}
+		if err := r.Delete("billing", "welcome"); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if _, ok := r.Get("billing", "welcome"); ok {
+			t.Errorf("got deleted template")
+		}
+	})
+
+	t.Run("case:invalid names", func(t *testing.T) {
+		r := NewRegistry(0)
+		template, _ := NewTemplate("x")
+		for _, name := range []string{"", "..", "a/b"} {
+			if err := r.Publish("ns", name, template); !errors.Is(err, RegistryInvalidNameError) {
+				t.Errorf("got %v  want:%v", err, RegistryInvalidNameError)
+			}
+		}
+	})
+
+	t.Run("case:concurrent reads", func(t *testing.T) {
+		r := NewRegistry(2)
+		publish(t, r, "v1", "hello {{name}}")
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					template, _ := r.Get("billing", "welcome")
+					if _, err := template.ExecString(map[string]string{"name": "Ada"}, true); err != nil {
+						t.Errorf("error %v", err)
+						return
+					}
+				}
+			}()
+		}
+		for i := 2; i < 50; i++ {
+			publish(t, r, "v"+strconv.Itoa(i), "hi {{name}}")
+		}
+		wg.Wait()
+	})
+
+	t.Run("case:save and load", func(t *testing.T) {
+		dir := t.TempDir()
+		r := NewRegistry(5)
+		publish(t, r, "v1", "hello {{name}}")
+		publish(t, r, "v2", "hi {{name}}")
+		current, _ := NewTemplate("[[name]]!", WithTagPair("[[", "]]"), WithVersion("v3"), WithSecretKeys("token"))
+		if err := r.Publish("billing", "welcome", current); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if err := r.Save(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		loaded := NewRegistry(5)
+		if err := loaded.Load(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, want := render(t, loaded), "Ada!"; got != want {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+		history := loaded.History("billing", "welcome")
+		if len(history) != 3 || history[0].Version() != "v3" || history[2].Version() != "v1" {
+			t.Fatalf("got %v  want 3 versions", history)
+		}
+		if history[0].Checksum() != current.Checksum() || !history[0].IsSecret("token") {
+			t.Errorf("got %v  want the saved template", history[0])
+		}
+
+		secret, _ := NewTemplate("{{secret.key}}")
+		if err := r.Publish("billing", "bound", secret.Bind(map[string]string{"secret.key": "k"})); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if err := r.Save(t.TempDir()); !errors.Is(err, RegistrySecretError) {
+			t.Errorf("got %v  want:%v", err, RegistrySecretError)
+		}
+	})
+
+	t.Run("case:save after delete", func(t *testing.T) {
+		dir := t.TempDir()
+		r := NewRegistry(5)
+		for _, name := range []string{"a", "b"} {
+			template, _ := NewTemplate("hi {{name}}")
+			if err := r.Publish("billing", name, template); err != nil {
+				t.Fatalf("error %v", err)
+			}
+		}
+		if err := r.Save(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		// files the registry did not write are kept.
+		other := filepath.Join(dir, "billing", "settings.json")
+		if err := os.WriteFile(other, []byte("{}"), 0o644); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		r.Delete("billing", "b")
+		if err := r.Save(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if _, err := os.Stat(other); err != nil {
+			t.Errorf("got %v  want the file kept", err)
+		}
+
+		loaded := NewRegistry(5)
+		if err := loaded.Load(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if got, want := loaded.Names("billing"), []string{"a"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v  want:%v", got, want)
+		}
+	})
+
+	t.Run("case:save bound template", func(t *testing.T) {
+		dir := t.TempDir()
+		r := NewRegistry(5)
+		template, _ := NewTemplate("x={{a}} y={{b}}", WithVersion("v1"))
+		bound := template.Bind(map[string]string{"a": "{{b}}"})
+		if err := r.Publish("billing", "bound", bound); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if err := r.Save(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+
+		loaded := NewRegistry(5)
+		if err := loaded.Load(dir); err != nil {
+			t.Fatalf("error %v", err)
+		}
+		got, ok := loaded.Get("billing", "bound")
+		if !ok {
+			t.Fatalf("got no template")
+		}
+		s, _ := got.ExecString(map[string]string{"b": "B"}, true)
+		if want := "x={{b}} y=B"; s != want || got.Version() != "v1" || got.Checksum() != bound.Checksum() {
+			t.Errorf("got %q %v  want:%q", s, got.Version(), want)
+		}
+	})
+}
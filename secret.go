@@ -0,0 +1,87 @@
+package easytmpl
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SecretKeyPrefix marks a placeholder as secret by convention, e.g. `{{secret.api_key}}`.
+// Other keys can be marked as secret with WithSecretKeys.
+const SecretKeyPrefix = "secret."
+
+// Redacted replaces the values of secret placeholders in errors, source maps, hooks and debug output.
+// The rendered output always contains the real values.
+const Redacted = "[REDACTED]"
+
+// IsSecret reports whether the placeholder key is secret, either because it starts with SecretKeyPrefix
+// or because it was passed to WithSecretKeys.
+func (t *Template) IsSecret(key string) bool {
+	if strings.HasPrefix(key, SecretKeyPrefix) {
+		return true
+	}
+	_, ok := t.secrets[key]
+	return ok
+}
+
+// hasSecret reports whether the placeholder at index i is secret or, in ShellSyntax,
+// whether the words of its expansion reference a secret parameter.
+func (t *Template) hasSecret(i int) bool {
+	if t.IsSecret(b2s(t.args[i])) {
+		return true
+	}
+	if t.shell == nil || t.shell[i].word == nil {
+		return false
+	}
+	keys := t.shell[i].word.Placeholder()
+	t.shell[i].word.shellWordKeys(keys)
+	for k := range keys {
+		if t.IsSecret(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedLookup wraps lookup so that non-empty secret values are reported as Redacted.
+func (t *Template) redactedLookup(lookup func(key string) (string, bool)) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := lookup(key)
+		if ok && v != "" && t.IsSecret(key) {
+			return Redacted, true
+		}
+		return v, ok
+	}
+}
+
+// redacts reports whether the content range [start, end) overlaps a secret value bound with Bind.
+func (t *Template) redacts(start, end int) bool {
+	for _, r := range t.redactions {
+		if r[0] < end && start < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the template source for debugging, with the values of secret placeholders bound with Bind
+// replaced by Redacted.
+func (t *Template) String() string {
+	if t.source != nil {
+		return string(t.source)
+	}
+	var sb strings.Builder
+	last := 0
+	for _, r := range t.redactions {
+		sb.Write(t.content[last:r[0]])
+		sb.WriteString(Redacted)
+		last = r[1]
+	}
+	sb.Write(t.content[last:])
+	return sb.String()
+}
+
+// GoString implements fmt.GoStringer so that `%#v` does not dump the template's internal fields,
+// which may hold secret values bound with Bind.
+func (t *Template) GoString() string {
+	return "easytmpl.Template(" + strconv.Quote(t.String()) + ")"
+}
@@ -0,0 +1,71 @@
+package easytmpl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTemplate_Secret(t *testing.T) {
+	template, err := NewTemplate("user={{user}} pass={{password}} key={{secret.key}}", WithSecretKeys("password"))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	for key, want := range map[string]bool{"user": false, "password": true, "secret.key": true} {
+		if got := template.IsSecret(key); got != want {
+			t.Errorf("IsSecret(%q) = %v  want:%v", key, got, want)
+		}
+	}
+
+	t.Run("case:rendered output contains real values", func(t *testing.T) {
+		got, err := template.ExecString(map[string]string{"user": "u", "password": "p4ss", "secret.key": "k3y"}, true)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		if want := "user=u pass=p4ss key=k3y"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:bound secrets are redacted in debug output", func(t *testing.T) {
+		bound := template.Bind(map[string]string{"password": "p4ss"}).Bind(map[string]string{"user": "u", "secret.key": "k3y"})
+		want := "user=u pass=[REDACTED] key=[REDACTED]"
+		for _, got := range []string{bound.String(), fmt.Sprint(bound), fmt.Sprintf("%+v", bound), fmt.Sprintf("%#v", bound)} {
+			if !strings.Contains(got, want) || strings.Contains(got, "p4ss") || strings.Contains(got, "k3y") {
+				t.Errorf("got %q  want:%q", got, want)
+			}
+		}
+		got, err := bound.ExecString(nil, true)
+		if err != nil || got != "user=u pass=p4ss key=k3y" {
+			t.Errorf("got %q, %v", got, err)
+		}
+	})
+
+	t.Run("case:source map", func(t *testing.T) {
+		bound := template.Bind(map[string]string{"password": "p4ss"})
+		_, sm, err := bound.ExecWithSourceMap(map[string]string{"user": "u", "secret.key": "k3y"}, true)
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		var secrets []string
+		for _, span := range sm {
+			if span.Secret {
+				secrets = append(secrets, fmt.Sprintf("%d:%s", span.Kind, span.Key))
+			}
+		}
+		if got, want := strings.Join(secrets, ","), "0:,1:secret.key"; got != want {
+			t.Errorf("got %q  want:%q", got, want)
+		}
+	})
+
+	t.Run("case:shell error message", func(t *testing.T) {
+		template, err := NewTemplate("${HOST:?cannot connect with $secret_token}", WithSyntax(ShellSyntax), WithSecretKeys("secret_token"))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		_, err = template.ExecString(map[string]string{"secret_token": "t0ken"}, false)
+		if want := "HOST: cannot connect with [REDACTED]"; err == nil || err.Error() != want {
+			t.Errorf("got %v  want:%v", err, want)
+		}
+	})
+}
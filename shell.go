@@ -0,0 +1,218 @@
+package easytmpl
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+)
+
+// Syntax selects how NewTemplate recognises placeholders in the template content.
+type Syntax uint8
+
+const (
+	// TagSyntax recognises placeholders delimited by the template's tag pair, e.g. `{{name}}`. It is the default.
+	TagSyntax Syntax = iota
+
+	// ShellSyntax recognises POSIX shell parameter expansions as understood by envsubst:
+	// `$VAR`, `${VAR}`, and the `${VAR-word}`, `${VAR:-word}`, `${VAR+word}`, `${VAR:+word}`,
+	// `${VAR?word}` and `${VAR:?word}` forms. word may itself contain expansions.
+	// `${VAR=word}` and `${VAR:=word}` substitute like their `-` counterparts, since the values cannot be assigned.
+	// A `$` that does not start a valid expansion is kept as literal text.
+	ShellSyntax
+)
+
+// Syntax returns the syntax the template was parsed with, see WithSyntax.
+func (t *Template) Syntax() Syntax {
+	return t.syntax
+}
+
+// ShellParameterError is returned when a `${VAR?word}` or `${VAR:?word}` expansion finds VAR unset (or null).
+// It satisfies errors.Is against TemplateExecMissingParameterError.
+type ShellParameterError struct {
+	Name    string
+	Message string
+}
+
+// Error implements the error interface, formatting the error the way a POSIX shell does.
+func (e *ShellParameterError) Error() string {
+	if e.Message == "" {
+		return e.Name + ": parameter null or not set"
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Unwrap returns TemplateExecMissingParameterError.
+func (e *ShellParameterError) Unwrap() error {
+	return TemplateExecMissingParameterError
+}
+
+// shellExpansion describes the operator and word of a `${VAR<op>word}` expansion.
+// op is empty for `$VAR` and `${VAR}`.
+type shellExpansion struct {
+	op   string
+	word *Template
+}
+
+// parseShell parses the template content in ShellSyntax. Like parse, it populates args with the names
+// of the parameters and contentIntervalIdx with the intervals of static content; the operator and
+// word of each expansion are stored in shell, parallel to args.
+func (t *Template) parseShell() {
+	t.args, t.shell = nil, nil
+	t.contentIntervalIdx = nil
+
+	content := t.content
+	last := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '$' || i+1 == len(content) {
+			continue
+		}
+
+		if content[i+1] != '{' {
+			n := shellNameLen(content[i+1:])
+			if n == 0 {
+				continue
+			}
+			t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{last, i})
+			t.args = append(t.args, content[i+1:i+1+n])
+			t.shell = append(t.shell, shellExpansion{})
+			last = i + 1 + n
+			i = last - 1
+			continue
+		}
+
+		end := shellClosingBrace(content, i+2)
+		if end < 0 {
+			continue
+		}
+		inner := content[i+2 : end]
+		n := shellNameLen(inner)
+		if n == 0 {
+			continue
+		}
+		var exp shellExpansion
+		if n < len(inner) {
+			op := 1
+			if inner[n] == ':' {
+				op = 2
+			}
+			if n+op > len(inner) || bytes.IndexByte([]byte("-=+?"), inner[n+op-1]) < 0 {
+				continue
+			}
+			exp.op = string(inner[n : n+op])
+			exp.word = &Template{content: inner[n+op:], syntax: ShellSyntax, pairs: t.pairs, autoFill: t.autoFill, secrets: t.secrets}
+			exp.word.parseShell()
+		}
+		t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{last, i})
+		t.args = append(t.args, inner[:n])
+		t.shell = append(t.shell, exp)
+		last = end + 1
+		i = end
+	}
+	t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{last, math.MaxInt})
+}
+
+// shellNameLen returns the length of the shell variable name at the start of b, or 0 if there is none.
+func shellNameLen(b []byte) int {
+	for i, c := range b {
+		if c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9' {
+			continue
+		}
+		return i
+	}
+	return len(b)
+}
+
+// shellClosingBrace returns the index of the `}` that closes a `${` whose content starts at i,
+// skipping nested `${...}` expansions. It returns -1 if the expansion is not closed.
+func shellClosingBrace(b []byte, i int) int {
+	depth := 0
+	for ; i < len(b); i++ {
+		switch {
+		case b[i] == '$' && i+1 < len(b) && b[i+1] == '{':
+			depth++
+			i++
+		case b[i] == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// execShell renders a template in ShellSyntax, resolving parameters with lookup.
+// If strict is true, a bare `$VAR` or `${VAR}` whose parameter cannot be resolved returns TemplateExecMissingParameterError;
+// otherwise it expands to the auto-fill value, or to the empty string as envsubst does.
+func (t *Template) execShell(w io.Writer, lookup func(key string) (string, bool), strict bool) error {
+	return t.exec(w, func(w io.Writer, i int) error {
+		return t.expandShell(w, i, lookup, strict)
+	})
+}
+
+// expandShell writes the expansion of the parameter at index i to w.
+func (t *Template) expandShell(w io.Writer, i int, lookup func(key string) (string, bool), strict bool) error {
+	key := b2s(t.args[i])
+	v, ok := lookup(key)
+	exp := t.shell[i]
+
+	switch exp.op {
+	case "":
+		if !ok {
+			t.missingKey(key)
+			if strict {
+				return t.missingParameter(i)
+			}
+			if t.autoFill != nil {
+				_, err := w.Write(*t.autoFill)
+				return err
+			}
+		}
+	case "-", "=":
+		if !ok {
+			return exp.word.execShell(w, lookup, strict)
+		}
+	case ":-", ":=":
+		if v == "" {
+			return exp.word.execShell(w, lookup, strict)
+		}
+	case "+":
+		if ok {
+			return exp.word.execShell(w, lookup, strict)
+		}
+		return nil
+	case ":+":
+		if v != "" {
+			return exp.word.execShell(w, lookup, strict)
+		}
+		return nil
+	case "?", ":?":
+		if !ok || exp.op == ":?" && v == "" {
+			// the message may reference secret parameters; they are redacted in the error.
+			var msg bytes.Buffer
+			if err := exp.word.execShell(&msg, t.redactedLookup(lookup), strict); err != nil {
+				return err
+			}
+			return &ShellParameterError{Name: key, Message: msg.String()}
+		}
+	}
+	_, err := io.WriteString(w, v)
+	return err
+}
+
+// ExecEnv renders a template in ShellSyntax, resolving parameters from the environment.
+// Unset variables expand to the empty string, as with envsubst.
+// It returns TemplateModeError for templates in other syntaxes.
+func (t *Template) ExecEnv() (_ string, err error) {
+	defer t.wrapError(&err)
+	if t.syntax != ShellSyntax {
+		return "", TemplateModeError
+	}
+	var bb bytes.Buffer
+	bb.Grow(max(len(t.content)*2, t.capacity))
+	if err := t.execShell(&bb, os.LookupEnv, false); err != nil {
+		return "", err
+	}
+	return bb.String(), nil
+}
@@ -0,0 +1,121 @@
+package easytmpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_parseShell(t *testing.T) {
+	template, err := NewTemplate("$A-${B}-${C:-x${D}y}-$-${}-${E#x}-${F", WithSyntax(ShellSyntax))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	want := map[string]int{"A": 1, "B": 1, "C": 1}
+	if got := template.Placeholder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v  want:%v", got, want)
+	}
+	if got := template.shell[2].word.Placeholder(); !reflect.DeepEqual(got, map[string]int{"D": 1}) {
+		t.Errorf("got %v  want:%v", got, map[string]int{"D": 1})
+	}
+}
+
+func TestTemplate_ExecShell(t *testing.T) {
+	args := map[string]string{
+		"SET":   "value",
+		"EMPTY": "",
+		"OTHER": "other",
+	}
+	tests := []struct {
+		tpl  string
+		want string
+	}{
+		{tpl: "$SET/${SET}", want: "value/value"},
+		{tpl: "$SET_x ${SET}_x", want: " value_x"},
+		{tpl: "[$UNSET]", want: "[]"},
+		{tpl: "${UNSET-def} ${EMPTY-def} ${SET-def}", want: "def  value"},
+		{tpl: "${UNSET:-def} ${EMPTY:-def} ${SET:-def}", want: "def def value"},
+		{tpl: "${UNSET=def} ${EMPTY:=def}", want: "def def"},
+		{tpl: "[${UNSET+alt}] [${EMPTY+alt}] [${SET+alt}]", want: "[] [alt] [alt]"},
+		{tpl: "[${UNSET:+alt}] [${EMPTY:+alt}] [${SET:+alt}]", want: "[] [] [alt]"},
+		{tpl: "${UNSET:-${OTHER}-$SET}", want: "other-value"},
+		{tpl: "${EMPTY?msg}", want: ""},
+		{tpl: "$ $1 $$SET ${} ${SET#v} ${SET", want: "$ $1 $value ${} ${SET#v} ${SET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tpl, func(t *testing.T) {
+			template, err := NewTemplate(tt.tpl, WithSyntax(ShellSyntax))
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			got, err := template.ExecString(args, false)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q  want:%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate_ExecShellError(t *testing.T) {
+	tests := []struct {
+		tpl    string
+		strict bool
+		want   string
+	}{
+		{tpl: "${UNSET?}", want: "UNSET: parameter null or not set"},
+		{tpl: "${EMPTY:?must be set for $SET}", want: "EMPTY: must be set for value"},
+		{tpl: "$UNSET", strict: true, want: "missing parameter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tpl, func(t *testing.T) {
+			template, err := NewTemplate(tt.tpl, WithSyntax(ShellSyntax))
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			_, err = template.ExecString(map[string]string{"EMPTY": "", "SET": "value"}, tt.strict)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("got %v  want:%v", err, tt.want)
+			}
+			if !errors.Is(err, TemplateExecMissingParameterError) {
+				t.Errorf("%v is not %v", err, TemplateExecMissingParameterError)
+			}
+		})
+	}
+}
+
+func TestTemplate_ExecEnv(t *testing.T) {
+	t.Setenv("EASYTMPL_HOST", "localhost")
+	template, err := NewTemplate("http://${EASYTMPL_HOST}:${EASYTMPL_PORT:-8080}/", WithSyntax(ShellSyntax))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	got, err := template.ExecEnv()
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := "http://localhost:8080/"; got != want {
+		t.Errorf("got %q  want:%q", got, want)
+	}
+}
+
+func TestTemplate_ShellUnsupported(t *testing.T) {
+	template, err := NewTemplate("a=${A:-def} b=${B:?need b}", WithSyntax(ShellSyntax))
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	var bb bytes.Buffer
+	err = template.ExecuteFunc(&bb, func(w io.Writer, key string) (int, error) { return 0, nil })
+	if !errors.Is(err, TemplateModeError) || bb.Len() != 0 {
+		t.Errorf("got %q %v  want:%v", bb.String(), err, TemplateModeError)
+	}
+	err = template.ExecContext(context.Background(), &bb, func(ctx context.Context, w io.Writer, key string) (int, error) { return 0, nil })
+	if !errors.Is(err, TemplateModeError) || bb.Len() != 0 {
+		t.Errorf("got %q %v  want:%v", bb.String(), err, TemplateModeError)
+	}
+}
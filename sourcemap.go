@@ -0,0 +1,156 @@
+package easytmpl
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// SpanKind tells whether a Span of rendered output comes from static content or from a placeholder.
+type SpanKind uint8
+
+const (
+	// StaticSpan is output copied from the static content of the template.
+	StaticSpan SpanKind = iota
+
+	// PlaceholderSpan is output written for a placeholder.
+	PlaceholderSpan
+)
+
+// Span maps a byte range of rendered output back to the template.
+type Span struct {
+	Kind SpanKind
+
+	// Start and End are the byte offsets of the span in the rendered output.
+	Start, End int
+
+	// SourceStart and SourceEnd are the byte offsets in the template source, as written, of the static
+	// interval, or of the whole placeholder including its tags. A static interval spans the comments and
+	// directive tags dropped from it.
+	SourceStart, SourceEnd int
+
+	// Key is the key of the placeholder; it is empty for static spans.
+	Key string
+
+	// Index is the index of the placeholder in template order; it is -1 for static spans.
+	Index int
+
+	// Secret reports whether the span holds a secret value: the value of a secret placeholder, or
+	// static content containing a secret value bound with Bind. Its output should be redacted when displayed.
+	Secret bool
+}
+
+// SourceMap is the list of spans of a rendered output, ordered by output offset.
+type SourceMap []Span
+
+// Lookup returns the span that produced the output byte at offset.
+// Placeholders that rendered as the empty string never contain an offset.
+func (m SourceMap) Lookup(offset int) (Span, bool) {
+	i := sort.Search(len(m), func(i int) bool { return m[i].End > offset })
+	for ; i < len(m); i++ {
+		if m[i].Start > offset {
+			break
+		}
+		if m[i].Start < m[i].End {
+			return m[i], true
+		}
+	}
+	return Span{}, false
+}
+
+// countingWriter is an io.Writer that counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+// Write implements the io.Writer interface.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// ExecWithSourceMap renders the template like ExecString and also returns a SourceMap that maps each byte
+// range of the output to the static interval or placeholder that produced it. Empty static intervals are
+// omitted; every placeholder has a span, even when it renders as the empty string.
+// It returns TemplateModeError for templates in Mustache mode.
+func (t *Template) ExecWithSourceMap(args map[string]string, strict bool) (_ string, _ SourceMap, err error) {
+	defer t.wrapError(&err)
+	if t.mustache {
+		return "", nil, TemplateModeError
+	}
+	if strict && t.syntax == TagSyntax {
+		for i, a := range t.args {
+			if _, ok := args[b2s(a)]; !ok {
+				t.missingKey(b2s(a))
+				return "", nil, t.missingParameter(i)
+			}
+		}
+	}
+
+	var bb bytes.Buffer
+	bb.Grow(max(len(t.content)*2, t.capacity))
+	cw := &countingWriter{w: &bb}
+	spans := make(SourceMap, 0, 2*len(t.contentIntervalIdx))
+	lookup := func(key string) (string, bool) {
+		v, ok := args[key]
+		return v, ok
+	}
+
+	static := func(i, end int) {
+		start := 0
+		if len(spans) > 0 {
+			start = spans[len(spans)-1].End
+		}
+		if start == end {
+			return
+		}
+		spans = append(spans, Span{
+			Kind:        StaticSpan,
+			Start:       start,
+			End:         end,
+			SourceStart: t.sourceOffset(t.contentIntervalIdx[i][0], false),
+			SourceEnd:   t.sourceOffset(min(t.contentIntervalIdx[i][1], len(t.content)), true),
+			Index:       -1,
+			Secret:      t.redacts(t.contentIntervalIdx[i][0], min(t.contentIntervalIdx[i][1], len(t.content))),
+		})
+	}
+
+	err = t.exec(cw, func(w io.Writer, i int) error {
+		static(i, cw.n)
+		start := cw.n
+		var err error
+		if t.syntax == ShellSyntax {
+			err = t.expandShell(w, i, lookup, strict)
+		} else {
+			err = t.execArg(w, i, args)
+		}
+		spans = append(spans, Span{
+			Kind:        PlaceholderSpan,
+			Start:       start,
+			End:         cw.n,
+			SourceStart: t.sourceOffset(t.contentIntervalIdx[i][1], false),
+			SourceEnd:   t.sourceOffset(t.contentIntervalIdx[i+1][0], true),
+			Key:         b2s(t.args[i]),
+			Index:       i,
+			Secret:      t.hasSecret(i),
+		})
+		return err
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	static(len(t.contentIntervalIdx)-1, cw.n)
+	return bb.String(), spans, nil
+}
+
+// LineColumn converts a byte offset in the template source, such as Span.SourceStart,
+// to a 1-based line and column (in bytes).
+func (t *Template) LineColumn(offset int) (line, column int) {
+	src := t.sourceText()
+	offset = min(max(offset, 0), len(src))
+	line = 1 + bytes.Count(src[:offset], []byte{'\n'})
+	column = offset - bytes.LastIndexByte(src[:offset], '\n')
+	return line, column
+}
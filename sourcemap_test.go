@@ -0,0 +1,41 @@
+package easytmpl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTemplate_ExecWithSourceMap(t *testing.T) {
+	template, err := NewTemplate("a: {{a}}\nb: {{b}}{{c}}")
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	got, sm, err := template.ExecWithSourceMap(map[string]string{"a": "1", "b": "two", "c": ""}, true)
+	if err != nil {
+		t.Fatalf("error %v", err)
+	}
+	if want := "a: 1\nb: two"; got != want {
+		t.Errorf("got %q  want:%q", got, want)
+	}
+	want := SourceMap{
+		{Kind: StaticSpan, Start: 0, End: 3, SourceStart: 0, SourceEnd: 3, Index: -1},
+		{Kind: PlaceholderSpan, Start: 3, End: 4, SourceStart: 3, SourceEnd: 8, Key: "a", Index: 0},
+		{Kind: StaticSpan, Start: 4, End: 8, SourceStart: 8, SourceEnd: 12, Index: -1},
+		{Kind: PlaceholderSpan, Start: 8, End: 11, SourceStart: 12, SourceEnd: 17, Key: "b", Index: 1},
+		{Kind: PlaceholderSpan, Start: 11, End: 11, SourceStart: 17, SourceEnd: 22, Key: "c", Index: 2},
+	}
+	if !reflect.DeepEqual(sm, want) {
+		t.Fatalf("got %+v\nwant:%+v", sm, want)
+	}
+
+	span, ok := sm.Lookup(9)
+	if !ok || span.Key != "b" {
+		t.Fatalf("got %+v, %v", span, ok)
+	}
+	if line, col := template.LineColumn(span.SourceStart); line != 2 || col != 4 {
+		t.Errorf("got %d:%d  want:2:4", line, col)
+	}
+	if _, ok := sm.Lookup(11); ok {
+		t.Errorf("got span past the end of the output")
+	}
+}
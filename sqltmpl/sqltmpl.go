@@ -0,0 +1,250 @@
+// Package sqltmpl renders easytmpl templates into parameterised SQL queries. Placeholders render as the bind
+// markers of the driver and their values are returned as the query arguments, instead of being substituted
+// into the query text:
+//
+//	t, _ := sqltmpl.Compile("SELECT * FROM {{table}} WHERE id = {{id}} AND state IN ({{states}})",
+//		sqltmpl.Dollar, sqltmpl.WithIdentifier("table", "users", "orders"))
+//	query, args, _ := t.Query(map[string]any{"table": "users", "id": 7, "states": []string{"new", "paid"}})
+//	// query: SELECT * FROM users WHERE id = $1 AND state IN ($2, $3)
+//	// args:  [7 new paid]
+//
+// Table and column names cannot be bound; identifier placeholders are substituted only if their value
+// is in an allow-list.
+package sqltmpl
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+var (
+	// BindStyleInvalidError indicates an unknown BindStyle.
+	BindStyleInvalidError = errors.New("invalid bind style")
+
+	// BindNameInvalidError indicates a placeholder key that is not a valid bind name in the Colon style,
+	// e.g. `{{user.id}}`; names consist of letters, digits and underscores.
+	BindNameInvalidError = errors.New("invalid bind name")
+
+	// IdentifierNotAllowedError indicates a value of an identifier placeholder that is not in its allow-list.
+	IdentifierNotAllowedError = errors.New("identifier is not allowed")
+
+	// ListEmptyError indicates an empty slice value, which would render an invalid list such as `IN ()`.
+	ListEmptyError = errors.New("list value is empty")
+
+	// PlaceholderQuotedError indicates a placeholder inside a quoted string literal, e.g. `'{{name}}'`,
+	// where a bind marker would be taken literally. A placeholder is taken as quoted if the query text before it
+	// holds an odd number of `'`; values of identifier placeholders are not counted, but an apostrophe in a
+	// comment, e.g. `-- don't`, is, so queries with such comments fail with this error.
+	PlaceholderQuotedError = errors.New("placeholder is inside a string literal")
+
+	// BindNameConflictError indicates, in the Colon style, that the name given to an element of a list value,
+	// e.g. `states_1` for the first element of states, is also the key of a placeholder.
+	BindNameConflictError = errors.New("bind name conflict")
+)
+
+// BindStyle selects the bind markers placeholders render as.
+type BindStyle int
+
+const (
+	// Question renders `?` for each value, as used by MySQL and SQLite.
+	Question BindStyle = iota
+	// Dollar renders `$1`, `$2`, …, as used by PostgreSQL.
+	Dollar
+	// Colon renders `:name`, as used by Oracle; the arguments are sql.NamedArg values. The elements of a list
+	// value of name are named `name_1`, `name_2`, …
+	Colon
+	// AtP renders `@p1`, `@p2`, …, as used by SQL Server.
+	AtP
+)
+
+// Option configures a Template.
+type Option func(*Template) error
+
+// WithIdentifier makes key an identifier placeholder: its value, a string, is substituted into the query
+// text if it is one of allowed, e.g. a table or column name, and the query fails otherwise.
+func WithIdentifier(key string, allowed ...string) Option {
+	return func(t *Template) error {
+		set := t.identifiers[key]
+		if set == nil {
+			set = make(map[string]struct{}, len(allowed))
+			t.identifiers[key] = set
+		}
+		for _, a := range allowed {
+			set[a] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// WithTemplateOptions sets the options the query is compiled with, e.g. easytmpl.WithTagPair.
+// Mustache mode is not supported.
+func WithTemplateOptions(opts ...easytmpl.OptionHandler) Option {
+	return func(t *Template) error {
+		t.opts = append(t.opts, opts...)
+		return nil
+	}
+}
+
+// Template is a compiled SQL template. It is safe for concurrent use.
+type Template struct {
+	tpl         *easytmpl.Template
+	style       BindStyle
+	identifiers map[string]map[string]struct{}
+	opts        []easytmpl.OptionHandler
+}
+
+// Compile compiles the SQL template query, whose placeholders render as bind markers of style.
+// Placeholders must not be quoted: write `name = {{name}}`, not `name = '{{name}}'`.
+func Compile(query string, style BindStyle, opts ...Option) (*Template, error) {
+	if style < Question || style > AtP {
+		return nil, BindStyleInvalidError
+	}
+	t := &Template{style: style, identifiers: map[string]map[string]struct{}{}}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return nil, err
+		}
+	}
+	tpl, err := easytmpl.NewTemplate(query, t.opts...)
+	if err != nil {
+		return nil, err
+	}
+	if style == Colon {
+		for k := range tpl.Placeholder() {
+			if _, ok := t.identifiers[k]; !ok && !isBindName(k) {
+				return nil, fmt.Errorf("%w: %q", BindNameInvalidError, k)
+			}
+		}
+	}
+	t.tpl = tpl
+	return t, nil
+}
+
+// Query renders the query and returns it with its arguments, in the order of their bind markers.
+// Each placeholder renders as a bind marker, or as a comma-separated list of markers if its value is a slice
+// or an array other than a byte slice or a driver.Valuer, e.g. for `IN ({{ids}})`. In the Dollar, Colon and AtP
+// styles, placeholders with the same key share their markers. Identifier placeholders render their value.
+// It returns an error wrapping easytmpl.TemplateExecMissingParameterError if a key is missing in args.
+func (t *Template) Query(args map[string]any) (string, []any, error) {
+	var (
+		bb      bytes.Buffer
+		values  []any
+		markers = map[string]string{}
+		names   = map[string]struct{}{}
+		// quotes counts the `'` of the static content up to offset last of bb.
+		quotes int
+		last   int
+	)
+	// marker appends v to values and returns its bind marker.
+	marker := func(name string, v any) (string, error) {
+		values = append(values, v)
+		switch t.style {
+		case Dollar:
+			return "$" + strconv.Itoa(len(values)), nil
+		case Colon:
+			if _, ok := names[name]; ok {
+				return "", fmt.Errorf("%w: %q", BindNameConflictError, name)
+			}
+			names[name] = struct{}{}
+			values[len(values)-1] = sql.Named(name, v)
+			return ":" + name, nil
+		case AtP:
+			return "@p" + strconv.Itoa(len(values)), nil
+		}
+		return "?", nil
+	}
+	// write writes the value of a placeholder to w, leaving it out of the quotes count.
+	write := func(w io.Writer, s string) (int, error) {
+		n, err := io.WriteString(w, s)
+		last = bb.Len()
+		return n, err
+	}
+
+	err := t.tpl.ExecuteFunc(&bb, func(w io.Writer, key string) (int, error) {
+		// the static content before the placeholder has already been written to bb.
+		quotes += bytes.Count(bb.Bytes()[last:], []byte("'"))
+		if quotes%2 != 0 {
+			return 0, fmt.Errorf("%w: %q", PlaceholderQuotedError, key)
+		}
+		v, ok := args[key]
+		if !ok {
+			return 0, fmt.Errorf("%w: %q", easytmpl.TemplateExecMissingParameterError, key)
+		}
+		if allowed, ok := t.identifiers[key]; ok {
+			s, _ := v.(string)
+			if _, ok := allowed[s]; !ok || s == "" {
+				return 0, fmt.Errorf("%w: %q for %q", IdentifierNotAllowedError, fmt.Sprint(v), key)
+			}
+			return write(w, s)
+		}
+		if m, ok := markers[key]; ok && t.style != Question {
+			return write(w, m)
+		}
+
+		var m string
+		if rv := reflect.ValueOf(v); isList(v, rv) {
+			if rv.Len() == 0 {
+				return 0, fmt.Errorf("%w: %q", ListEmptyError, key)
+			}
+			var sb bytes.Buffer
+			for i := 0; i < rv.Len(); i++ {
+				if i > 0 {
+					sb.WriteString(", ")
+				}
+				em, err := marker(key+"_"+strconv.Itoa(i+1), rv.Index(i).Interface())
+				if err != nil {
+					return 0, err
+				}
+				sb.WriteString(em)
+			}
+			m = sb.String()
+		} else {
+			var err error
+			if m, err = marker(key, v); err != nil {
+				return 0, err
+			}
+		}
+		markers[key] = m
+		return write(w, m)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return bb.String(), values, nil
+}
+
+// isList reports whether v, whose reflect.Value is rv, is expanded into a list of bind markers.
+func isList(v any, rv reflect.Value) bool {
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
+	switch rv.Kind() {
+	case reflect.Slice:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	}
+	return false
+}
+
+// isBindName reports whether s is a valid name for a `:name` bind marker.
+func isBindName(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
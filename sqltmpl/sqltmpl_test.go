@@ -0,0 +1,129 @@
+package sqltmpl
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+func TestTemplate_Query(t *testing.T) {
+	const query = "SELECT {{col}} FROM {{table}} WHERE id = {{id}} AND state IN ({{states}}) AND owner = {{id}}"
+	args := map[string]any{"col": "name", "table": "users", "id": 7, "states": []string{"new", "paid"}}
+	cases := []struct {
+		name      string
+		style     BindStyle
+		wantQuery string
+		wantArgs  []any
+	}{
+		{"question", Question, "SELECT name FROM users WHERE id = ? AND state IN (?, ?) AND owner = ?", []any{7, "new", "paid", 7}},
+		{"dollar", Dollar, "SELECT name FROM users WHERE id = $1 AND state IN ($2, $3) AND owner = $1", []any{7, "new", "paid"}},
+		{"colon", Colon, "SELECT name FROM users WHERE id = :id AND state IN (:states_1, :states_2) AND owner = :id",
+			[]any{sql.Named("id", 7), sql.Named("states_1", "new"), sql.Named("states_2", "paid")}},
+		{"at p", AtP, "SELECT name FROM users WHERE id = @p1 AND state IN (@p2, @p3) AND owner = @p1", []any{7, "new", "paid"}},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := Compile(query, c.style, WithIdentifier("table", "users", "orders"), WithIdentifier("col", "id", "name"))
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			q, a, err := template.Query(args)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if q != c.wantQuery {
+				t.Errorf("got %q  want:%q", q, c.wantQuery)
+			}
+			if !reflect.DeepEqual(a, c.wantArgs) {
+				t.Errorf("got %v  want:%v", a, c.wantArgs)
+			}
+		})
+	}
+
+	t.Run("case:values are not substituted", func(t *testing.T) {
+		template, _ := Compile("SELECT * FROM users WHERE name = {{name}} AND born < {{t}} AND data = {{data}}", Question)
+		born := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+		q, a, err := template.Query(map[string]any{"name": "x' OR '1'='1", "t": born, "data": []byte("raw")})
+		want := []any{"x' OR '1'='1", born, []byte("raw")}
+		if err != nil || q != "SELECT * FROM users WHERE name = ? AND born < ? AND data = ?" || !reflect.DeepEqual(a, want) {
+			t.Errorf("got %q %v %v  want:%v", q, a, err, want)
+		}
+	})
+
+	t.Run("case:tag pair", func(t *testing.T) {
+		template, _ := Compile("SELECT * FROM t WHERE a = <%a%>", Dollar, WithTemplateOptions(easytmpl.WithTagPair("<%", "%>")))
+		if q, _, _ := template.Query(map[string]any{"a": 1}); q != "SELECT * FROM t WHERE a = $1" {
+			t.Errorf("got %q  want:%q", q, "SELECT * FROM t WHERE a = $1")
+		}
+	})
+}
+
+func TestTemplate_QueryErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		style BindStyle
+		args  map[string]any
+		want  error
+	}{
+		{"identifier not allowed", "SELECT * FROM {{table}}", Question, map[string]any{"table": "users; DROP TABLE users"}, IdentifierNotAllowedError},
+		{"identifier not a string", "SELECT * FROM {{table}}", Question, map[string]any{"table": 1}, IdentifierNotAllowedError},
+		{"missing", "SELECT * FROM users WHERE id = {{id}}", Question, nil, easytmpl.TemplateExecMissingParameterError},
+		{"empty list", "SELECT * FROM users WHERE id IN ({{ids}})", Dollar, map[string]any{"ids": []int{}}, ListEmptyError},
+		{"quoted", "SELECT * FROM users WHERE name = '{{name}}'", Question, map[string]any{"name": "x"}, PlaceholderQuotedError},
+	}
+	for _, c := range cases {
+		t.Run("case:"+c.name, func(t *testing.T) {
+			template, err := Compile(c.query, c.style, WithIdentifier("table", "users"))
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			if _, _, err := template.Query(c.args); !errors.Is(err, c.want) {
+				t.Errorf("got %v  want:%v", err, c.want)
+			}
+		})
+	}
+
+	t.Run("case:bind name conflict", func(t *testing.T) {
+		for _, query := range []string{
+			"SELECT * FROM t WHERE state IN ({{states}}) AND s = {{states_1}}",
+			"SELECT * FROM t WHERE s = {{states_1}} AND state IN ({{states}})",
+		} {
+			template, err := Compile(query, Colon)
+			if err != nil {
+				t.Fatalf("error %v", err)
+			}
+			_, _, err = template.Query(map[string]any{"states": []string{"new", "paid"}, "states_1": "x"})
+			if !errors.Is(err, BindNameConflictError) {
+				t.Errorf("got %v  want:%v", err, BindNameConflictError)
+			}
+		}
+	})
+
+	t.Run("case:quote in identifier value", func(t *testing.T) {
+		template, err := Compile("SELECT {{col}} FROM t WHERE id = {{id}}", Question, WithIdentifier("col", "it's"))
+		if err != nil {
+			t.Fatalf("error %v", err)
+		}
+		q, _, err := template.Query(map[string]any{"col": "it's", "id": 1})
+		if want := "SELECT it's FROM t WHERE id = ?"; q != want || err != nil {
+			t.Errorf("got %q %v  want:%q", q, err, want)
+		}
+	})
+
+	t.Run("case:bind name", func(t *testing.T) {
+		if _, err := Compile("SELECT * FROM users WHERE id = {{user.id}}", Colon); !errors.Is(err, BindNameInvalidError) {
+			t.Errorf("got %v  want:%v", err, BindNameInvalidError)
+		}
+	})
+
+	t.Run("case:bind style", func(t *testing.T) {
+		if _, err := Compile("SELECT 1", BindStyle(9)); !errors.Is(err, BindStyleInvalidError) {
+			t.Errorf("got %v  want:%v", err, BindStyleInvalidError)
+		}
+	})
+}
@@ -49,10 +49,10 @@ func NewTagPair(start, end string) (*TagPair, error) {
 // invalid tag will return an error.
 func checkTagInvalid(tag []byte) error {
 	if len(tag) == 0 {
-		return TagEmptyError
+		return newDiagnostic(TagEmptyError, "", -1, "tags must be at least one character long")
 	}
-	if bytes.Contains(tag, []byte{' '}) {
-		return TagContainSpaceError
+	if i := bytes.IndexByte(tag, ' '); i >= 0 {
+		return newDiagnostic(TagContainSpaceError, string(tag), i, "remove the spaces from the tag")
 	}
 	return nil
 }
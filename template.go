@@ -20,68 +20,170 @@ type Template struct {
 	content            []byte
 	contentIntervalIdx [][2]int
 	args               [][]byte
+	formats            []valueFormat
+	formatSpecs        bool
+	lists              []listSpec
+	listSpecs          bool
+	positions          []int
+	positional         int
 	pairs              *TagPair
 	capacity           int
 	autoFill           *[]byte
+	mustache           bool
+	partials           map[string]string
+	nodes              []*mustacheNode
+	syntax             Syntax
+	shell              []shellExpansion
+	constraints        map[string]func(string) bool
+	maxTemplateSize    int
+	maxOutputSize      int
+	maxValueSize       int
+	secrets            map[string]struct{}
+	redactions         [][2]int
+	hooks              *Hooks
+	name               string
+	version            string
+	checksum           string
+	indentAll          bool
+	indentKeys         map[string]struct{}
+	indents            [][]byte
+	// source is the content as written when parse dropped comments or raw block tags from content;
+	// sourceOffsets maps offsets in content to offsets in source, see sourceOffset.
+	source        []byte
+	sourceOffsets [][2]int
 }
 
 // NewTemplate creates a new Template instance with the provided template string and optional configurations.
 // If no tag pair is specified, the default tag pair `{{` and `}}` will be used.
+// Comments such as `{{! note }}` are dropped from the output, and the content of raw blocks such as
+// `{{raw}}{{literal}}{{/raw}}` is output verbatim, without looking for placeholders.
+// The tags of blocks such as `{{block "body"}}default{{/block}}` are dropped and defines such as
+// `{{define "body"}}…{{/define}}` are not output; see Extend.
 // It returns an error if the template content is empty or consists solely of whitespace.
 func NewTemplate(tpl string, opts ...OptionHandler) (*Template, error) {
+	if err := checkContent(tpl); err != nil {
+		return nil, err
+	}
 
-	if len(tpl) == 0 {
-		return nil, TemplateContentEmptyError
+	template := &Template{
+		content: s2b(tpl),
+	}
+	for _, opt := range opts {
+		if err := opt(template); err != nil {
+			template.wrapError(&err)
+			return nil, err
+		}
 	}
+	if err := template.compile(); err != nil {
+		template.wrapError(&err)
+		return nil, err
+	}
+	return template, nil
+}
 
-	content := s2b(tpl)
+// checkContent returns an error if the template content is empty or consists solely of whitespace.
+func checkContent(tpl string) error {
+	if len(tpl) == 0 {
+		return newDiagnostic(TemplateContentEmptyError, tpl, -1, "the template must contain at least one non-blank character")
+	}
 
 	var isAllBlank = true
-	for _, c := range content {
-		if c != ' ' {
+	for i := 0; i < len(tpl); i++ {
+		if tpl[i] != ' ' {
 			isAllBlank = false
 			break
 		}
 	}
 	if isAllBlank {
-		return nil, TemplateContentEmptyError
+		return newDiagnostic(TemplateContentEmptyError, tpl, -1, "the template must contain at least one non-blank character")
+	}
+	return nil
+}
+
+// compile checks the size of the content and parses it according to the options already applied.
+func (t *Template) compile() error {
+	t.checksum = checksumOf(t.content)
+
+	if t.maxTemplateSize > 0 && len(t.content) > t.maxTemplateSize {
+		return &LimitExceededError{Limit: TemplateLimit, Max: t.maxTemplateSize, Index: -1}
 	}
 
-	template := &Template{
-		content: content,
+	if t.pairs == nil {
+		t.pairs = DefaultTagPair
 	}
-	for _, opt := range opts {
-		if err := opt(template); err != nil {
-			return nil, err
+	if t.mustache {
+		nodes, err := parseMustache(t.content, t.pairs.start, t.pairs.end)
+		if err != nil {
+			return err
 		}
+		t.nodes = nodes
+		return nil
+	}
+	if t.syntax == ShellSyntax {
+		t.parseShell()
+	} else if err := t.parse(); err != nil {
+		return err
 	}
+	t.parseIndents()
+	return nil
+}
 
-	if template.pairs == nil {
-		template.pairs = DefaultTagPair
+// recompile returns a copy of t with the options of t and the parsed content.
+func (t *Template) recompile(content []byte) (*Template, error) {
+	nt := *t
+	nt.content, nt.source, nt.sourceOffsets = content, nil, nil
+	nt.contentIntervalIdx, nt.args, nt.formats, nt.lists, nt.positions, nt.shell, nt.nodes = nil, nil, nil, nil, nil, nil, nil
+	nt.positional = 0
+	nt.redactions = nil
+	if err := nt.compile(); err != nil {
+		return nil, err
 	}
-	template.parse()
-	return template, nil
+	return &nt, nil
 }
 
 // parse parses the template content to identify placeholders and their positions based on the defined tag pairs.
 // It populates the args slice with the identified placeholders and
 // the contentIntervalIdx slice with the intervals of static content.
-func (t *Template) parse() {
+// Comments are dropped from the content and raw blocks are not scanned for placeholders.
+func (t *Template) parse() error {
+	raws, err := t.parseComments()
+	if err != nil {
+		return err
+	}
+	t.args, t.contentIntervalIdx = nil, nil
+	start, from := 0, 0
+	for _, r := range raws {
+		start = t.parseRange(from, r[0], start)
+		from = r[1]
+	}
+	start = t.parseRange(from, len(t.content), start)
+	t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{start, math.MaxInt})
+	if t.listSpecs {
+		t.parseLists()
+	}
+	if t.formatSpecs {
+		t.parseFormats()
+	}
+	t.parsePositions()
+	return nil
+}
+
+// parseRange parses the placeholders of the content range [from, to), whose static content starts at
+// staticStart. It returns the start of the static content following the last placeholder.
+func (t *Template) parseRange(from, to, staticStart int) int {
 	slen := len(t.pairs.start)
 	elen := len(t.pairs.end)
-	en := len(t.content) - elen
-	sn := len(t.content) - slen - elen
-	if en <= 0 {
-		t.args = nil
-		t.contentIntervalIdx = nil
-		return
+	en := to - elen
+	sn := to - slen - elen
+	if en <= from {
+		return staticStart
 	}
 
-	var argStartIdx, argEndIdx = 0 - elen - 1, 0 - elen
-	var lastStartIdx, lastEndIdx = argStartIdx, argEndIdx
+	var argStartIdx, argEndIdx = from - elen - 1, staticStart - elen
+	var lastStartIdx, lastEndIdx = argStartIdx, from - elen
 	var j int
 
-	for i := 0; i <= en; i++ {
+	for i := from; i <= en; i++ {
 
 		if i < sn && bytes.Equal(t.content[i:i+slen], t.pairs.start) {
 			j = argStartIdx
@@ -99,7 +201,7 @@ func (t *Template) parse() {
 					t.args = append(t.args, t.content[argStartIdx+slen:i])
 					lastStartIdx = argStartIdx
 					argEndIdx = i
-				} else if j >= 0 {
+				} else if j >= from {
 					t.args = append(t.args, t.content[j+slen:i])
 					t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{argEndIdx + elen, j})
 
@@ -112,11 +214,11 @@ func (t *Template) parse() {
 			lastEndIdx = i
 		}
 	}
-	t.contentIntervalIdx = append(t.contentIntervalIdx, [2]int{argEndIdx + elen, math.MaxInt})
-
+	return argEndIdx + elen
 }
 
 // Placeholder get all placeholders of the template.
+// With WithFormatSpecs, the format spec of a placeholder, e.g. `%.2f` in `{{price:%.2f}}`, is not part of its key.
 // it returns a map wherein each key is a template placeholder, and
 // its corresponding value is the count of that placeholder.
 func (t *Template) Placeholder() map[string]int {
@@ -133,11 +235,48 @@ func (t *Template) Placeholder() map[string]int {
 // If strict is true, it returns an error if any placeholder in the template
 // does not have a corresponding entry in args.
 // If strict is false, placeholders without corresponding entries in args will remain unchanged in the output.
+// In Mustache mode, args is used as the root context and strict reports variables that cannot be resolved.
+// With WithFormatSpecs, a format spec such as `{{name:%-10s}}` is applied to the value; see ExecValues.
+// With WithListSpecs, a query placeholder such as `{{?q}}` renders `q=` followed by the escaped value; see ExecLists.
+// In ShellSyntax, parameters are resolved from args with shell semantics instead of the environment.
 func (t *Template) ExecString(args map[string]string, strict bool) (string, error) {
+	if t.hooks == nil {
+		s, err := t.execString(args, strict)
+		t.wrapError(&err)
+		return s, err
+	}
+	start := t.hooks.renderStart(t)
+	s, err := t.execString(args, strict)
+	t.wrapError(&err)
+	t.hooks.renderEnd(t, start, len(s), err)
+	return s, err
+}
+
+// execString implements ExecString.
+func (t *Template) execString(args map[string]string, strict bool) (string, error) {
+	if t.mustache || t.syntax == ShellSyntax {
+		var bb bytes.Buffer
+		bb.Grow(max(len(t.content)*2, t.capacity))
+		var err error
+		if t.mustache {
+			r := t.newMustacheRenderer(&bb, strict)
+			err = r.render(t.nodes, []any{args})
+		} else {
+			err = t.execShell(&bb, func(key string) (string, bool) {
+				v, ok := args[key]
+				return v, ok
+			}, strict)
+		}
+		if err != nil {
+			return "", err
+		}
+		return bb.String(), nil
+	}
 	if strict {
-		for _, a := range t.args {
+		for i, a := range t.args {
 			if _, ok := args[string(a)]; !ok {
-				return "", TemplateExecMissingParameterError
+				t.missingKey(string(a))
+				return "", t.missingParameter(i)
 			}
 		}
 	}
@@ -148,30 +287,46 @@ func (t *Template) ExecString(args map[string]string, strict bool) (string, erro
 		bb.Grow(t.capacity)
 	}
 
-	err := t.exec(&bb, func(w io.Writer, key string) (int, error) {
-		if v, ok := args[key]; ok {
-			return w.Write(s2b(v))
-		} else if t.autoFill != nil {
-			return w.Write(*t.autoFill)
-		} else {
-			w.Write(t.pairs.start)
-			w.Write(s2b(key))
-			w.Write(t.pairs.end)
-			return len(t.pairs.start) + len(t.pairs.end) + len(key), nil
-		}
+	err := t.exec(&bb, func(w io.Writer, i int) error {
+		return t.execArg(w, i, args)
 	})
 
 	return bb.String(), err
 }
 
+// execArg writes the value of the placeholder at index i in args to w, as ExecString does in non-strict mode.
+func (t *Template) execArg(w io.Writer, i int, args map[string]string) error {
+	if v, ok := args[b2s(t.args[i])]; ok {
+		if t.formats != nil || t.lists != nil {
+			_, err := w.Write(t.appendArg(nil, i, v))
+			return err
+		}
+		_, err := w.Write(s2b(v))
+		return err
+	} else if t.autoFill != nil {
+		t.missingKey(b2s(t.args[i]))
+		_, err := w.Write(*t.autoFill)
+		return err
+	} else {
+		t.missingKey(b2s(t.args[i]))
+		_, err := w.Write(t.placeholder(i))
+		return err
+	}
+}
+
 // exec is a helper function that executes the template rendering process.
-func (t *Template) exec(b io.Writer, f func(w io.Writer, key string) (int, error)) error {
+// It writes the static content to b and calls f with the index of each placeholder in t.args.
+// Output and value size limits are enforced on everything written through the writer passed to f.
+func (t *Template) exec(b io.Writer, f func(w io.Writer, i int) error) error {
+	if lw, ok := t.newLimitWriter(b).(*limitWriter); ok {
+		return t.execLimited(lw, f)
+	}
 
+	iw := t.newIndentWriter()
 	for i := 0; i < len(t.contentIntervalIdx)-1; i++ {
 		c := t.content[t.contentIntervalIdx[i][0]:t.contentIntervalIdx[i][1]]
 		b.Write(c)
-		_, err := f(b, b2s(t.args[i]))
-		if err != nil {
+		if err := f(t.placeholderWriter(iw, b, i), i); err != nil {
 			return err
 		}
 	}
@@ -185,6 +340,29 @@ func (t *Template) exec(b io.Writer, f func(w io.Writer, key string) (int, error
 // ExecuteFunc renders the template using a custom function to handle each placeholder.
 // The function f is called for each placeholder with the writer and the placeholder key.
 // It returns the rendered string or an error if any occurs during the rendering process.
+// It returns TemplateModeError for templates in Mustache mode or in ShellSyntax, whose expansions depend on
+// whether a parameter is set, which f cannot report; use ExecEnv or ExecString for those.
 func (t *Template) ExecuteFunc(w io.Writer, f func(w io.Writer, key string) (int, error)) error {
-	return t.exec(w, f)
+	if t.hooks == nil {
+		err := t.executeFunc(w, f)
+		t.wrapError(&err)
+		return err
+	}
+	start := t.hooks.renderStart(t)
+	cw := &countingWriter{w: w}
+	err := t.executeFunc(cw, f)
+	t.wrapError(&err)
+	t.hooks.renderEnd(t, start, cw.n, err)
+	return err
+}
+
+// executeFunc implements ExecuteFunc.
+func (t *Template) executeFunc(w io.Writer, f func(w io.Writer, key string) (int, error)) error {
+	if t.mustache || t.syntax == ShellSyntax {
+		return TemplateModeError
+	}
+	return t.exec(w, func(w io.Writer, i int) error {
+		_, err := f(w, b2s(t.args[i]))
+		return err
+	})
 }
@@ -0,0 +1,60 @@
+package a
+
+import (
+	"github.com/tylitianrui/easytmpl"
+)
+
+const greeting = "Hello {{name}}, you have {{count}} messages"
+
+func supplied() {
+	t, _ := easytmpl.NewTemplate(greeting)
+	t.ExecString(map[string]string{"name": "Ada", "count": "3"}, true)
+}
+
+func missing() {
+	t, _ := easytmpl.NewTemplate(greeting, easytmpl.WithAutoFill("-"))
+	t.ExecString(map[string]string{"name": "Ada"}, false) // want `placeholder "count" of the template created at .* is never supplied`
+}
+
+func unused() {
+	t, err := easytmpl.NewTemplate("[[a]] {{b}}", easytmpl.WithTagPair("[[", "]]"))
+	if err != nil {
+		return
+	}
+	t.ExecValues(map[string]any{
+		"a": 1,
+		"b": 2, // want `key "b" is not a placeholder of the template`
+	}, true)
+}
+
+func invalid() {
+	easytmpl.NewTemplate("   ") // want `invalid template: template content is empty`
+}
+
+func skipped(key string) {
+	t, _ := easytmpl.NewTemplate(greeting)
+	t.ExecString(map[string]string{key: "x"}, true)
+
+	m, _ := easytmpl.NewTemplate("{{#a}}{{/a}}", easytmpl.WithMustache())
+	m.ExecString(map[string]string{}, true)
+
+	r, _ := easytmpl.NewTemplate(greeting)
+	r, _ = easytmpl.NewTemplate("{{other}}")
+	r.ExecString(map[string]string{"name": "Ada"}, true)
+}
+
+func lists() {
+	t, _ := easytmpl.NewTemplate("/search?{{?q}}&tags={{tags,}}", easytmpl.WithListSpecs())
+	t.ExecLists(map[string][]string{"q": {"go"}}, true) // want `placeholder "tags" of the template created at .* is never supplied`
+
+	u, _ := easytmpl.NewTemplate("{{a,b}}")
+	u.ExecString(map[string]string{"a,b": "x"}, true)
+}
+
+func specs() {
+	t, _ := easytmpl.NewTemplate("{{price:%.2f}} {{host:port}}", easytmpl.WithFormatSpecs())
+	t.ExecValues(map[string]any{"price": 9.5}, true) // want `placeholder "host" of the template created at .* is never supplied`
+
+	u, _ := easytmpl.NewTemplate("{{host:port}}")
+	u.ExecString(map[string]string{"host:port": "db:5432"}, true)
+}
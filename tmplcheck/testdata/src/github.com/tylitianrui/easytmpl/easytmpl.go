@@ -0,0 +1,32 @@
+// Package easytmpl is a stub of the easytmpl API used by the analyzer tests.
+package easytmpl
+
+type Template struct{}
+
+type OptionHandler func(*Template) error
+
+type Syntax int
+
+const ShellSyntax Syntax = 1
+
+func NewTemplate(tpl string, opts ...OptionHandler) (*Template, error) { return nil, nil }
+
+func WithTagPair(start, end string) OptionHandler { return nil }
+
+func WithAutoFill(s string) OptionHandler { return nil }
+
+func WithMustache() OptionHandler { return nil }
+
+func WithFormatSpecs() OptionHandler { return nil }
+
+func WithListSpecs() OptionHandler { return nil }
+
+func WithSyntax(s Syntax) OptionHandler { return nil }
+
+func (t *Template) ExecString(args map[string]string, strict bool) (string, error) { return "", nil }
+
+func (t *Template) ExecValues(args map[string]any, strict bool) (string, error) { return "", nil }
+
+func (t *Template) Placeholder() map[string]int { return nil }
+
+func (t *Template) ExecLists(args map[string][]string, strict bool) (string, error) { return "", nil }
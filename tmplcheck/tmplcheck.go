@@ -0,0 +1,252 @@
+// Package tmplcheck defines an Analyzer that checks literal easytmpl templates against the literal
+// arguments they are rendered with.
+//
+// It finds templates created by a call of easytmpl.NewTemplate with a constant template string, assigned to
+// a variable that is not assigned anywhere else, and the calls of ExecString, ExecValues and ExecWithSourceMap
+// on that variable with a map literal whose keys are constants. The template is parsed with the same tag pair
+// rules as at run time, including a WithTagPair option with constant tags, WithFormatSpecs and WithListSpecs, and the checker reports the
+// placeholders that the map literal does not supply and the keys of the map literal that are not placeholders.
+//
+// Templates created with other options changing how they are parsed, such as WithMustache or WithSyntax,
+// are not checked.
+//
+// The Analyzer can be run with `go vet -vettool=$(which easytmpl-vet)`, see cmd/easytmpl-vet.
+package tmplcheck
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/tylitianrui/easytmpl"
+)
+
+const pkgPath = "github.com/tylitianrui/easytmpl"
+
+// Analyzer reports placeholders of literal templates that are never supplied, and supplied keys that are
+// not placeholders.
+var Analyzer = &analysis.Analyzer{
+	Name:     "easytmpl",
+	Doc:      "check that literal easytmpl templates are rendered with the keys they use",
+	URL:      "https://pkg.go.dev/github.com/tylitianrui/easytmpl/tmplcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// execMethods are the methods of easytmpl.Template taking a map of arguments as their first parameter.
+var execMethods = map[string]bool{"ExecString": true, "ExecValues": true, "ExecWithSourceMap": true, "ExecLists": true}
+
+// literalTemplate is a template created from a constant string.
+type literalTemplate struct {
+	call        *ast.CallExpr
+	placeholder map[string]int
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	literals := make(map[*ast.CallExpr]*literalTemplate)
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		if call := n.(*ast.CallExpr); isFunc(pass, call.Fun, "NewTemplate") {
+			if t := parseLiteral(pass, call); t != nil {
+				literals[call] = t
+			}
+		}
+	})
+
+	templates := make(map[types.Object]*literalTemplate)
+	assigned := make(map[types.Object]int)
+	assign := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for _, l := range lhs {
+			if id, ok := ast.Unparen(l).(*ast.Ident); ok {
+				if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+					assigned[obj]++
+				}
+			}
+		}
+		if len(lhs) != 2 || len(rhs) != 1 {
+			return
+		}
+		id, ok := ast.Unparen(lhs[0]).(*ast.Ident)
+		if !ok {
+			return
+		}
+		call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		if t, ok := literals[call]; ok {
+			templates[pass.TypesInfo.ObjectOf(id)] = t
+		}
+	}
+
+	insp.Preorder([]ast.Node{(*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			assign(n.Lhs, n.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			assign(lhs, n.Values)
+		}
+	})
+	// variables whose address is taken may be assigned indirectly.
+	insp.Preorder([]ast.Node{(*ast.UnaryExpr)(nil)}, func(n ast.Node) {
+		if u := n.(*ast.UnaryExpr); u.Op == token.AND {
+			if id, ok := ast.Unparen(u.X).(*ast.Ident); ok {
+				assigned[pass.TypesInfo.ObjectOf(id)]++
+			}
+		}
+	})
+
+	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+		if !ok || !execMethods[sel.Sel.Name] || len(call.Args) == 0 || !isMethod(pass, sel) {
+			return
+		}
+		id, ok := ast.Unparen(sel.X).(*ast.Ident)
+		if !ok {
+			return
+		}
+		obj := pass.TypesInfo.ObjectOf(id)
+		t, ok := templates[obj]
+		if !ok || assigned[obj] != 1 {
+			return
+		}
+		lit, ok := ast.Unparen(call.Args[0]).(*ast.CompositeLit)
+		if !ok {
+			return
+		}
+		checkArgs(pass, call, lit, t)
+	})
+	return nil, nil
+}
+
+// checkArgs reports the placeholders of t missing from the map literal lit, and its keys that are not placeholders.
+func checkArgs(pass *analysis.Pass, call *ast.CallExpr, lit *ast.CompositeLit, t *literalTemplate) {
+	supplied := make(map[string]bool, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return
+		}
+		key, ok := constString(pass, kv.Key)
+		if !ok {
+			// keys computed at run time may supply any placeholder.
+			return
+		}
+		supplied[key] = true
+		if _, ok := t.placeholder[key]; !ok {
+			pass.Reportf(kv.Key.Pos(), "key %q is not a placeholder of the template", key)
+		}
+	}
+
+	var missing []string
+	for key := range t.placeholder {
+		if !supplied[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	for _, key := range missing {
+		pass.Reportf(lit.Pos(), "placeholder %q of the template created at %s is never supplied",
+			key, pass.Fset.Position(t.call.Pos()))
+	}
+}
+
+// parseLiteral parses the template created by call if its content and options are constant.
+// It reports templates that NewTemplate rejects.
+func parseLiteral(pass *analysis.Pass, call *ast.CallExpr) *literalTemplate {
+	if len(call.Args) == 0 || call.Ellipsis.IsValid() {
+		return nil
+	}
+	content, ok := constString(pass, call.Args[0])
+	if !ok {
+		return nil
+	}
+	var opts []easytmpl.OptionHandler
+	for _, arg := range call.Args[1:] {
+		opt, ok := ast.Unparen(arg).(*ast.CallExpr)
+		if !ok {
+			return nil
+		}
+		switch {
+		case isFunc(pass, opt.Fun, "WithTagPair") && len(opt.Args) == 2:
+			start, ok1 := constString(pass, opt.Args[0])
+			end, ok2 := constString(pass, opt.Args[1])
+			if !ok1 || !ok2 {
+				return nil
+			}
+			opts = append(opts, easytmpl.WithTagPair(start, end))
+		case isFunc(pass, opt.Fun, "WithFormatSpecs"):
+			opts = append(opts, easytmpl.WithFormatSpecs())
+		case isFunc(pass, opt.Fun, "WithListSpecs"):
+			opts = append(opts, easytmpl.WithListSpecs())
+		case isFunc(pass, opt.Fun, "WithMustache"), isFunc(pass, opt.Fun, "WithSyntax"):
+			return nil
+		case isFunc(pass, opt.Fun, ""):
+			// other options of the package do not change how the template is parsed.
+		default:
+			return nil
+		}
+	}
+
+	t, err := easytmpl.NewTemplate(content, opts...)
+	if err != nil {
+		pass.Reportf(call.Pos(), "invalid template: %v", err)
+		return nil
+	}
+	return &literalTemplate{call: call, placeholder: t.Placeholder()}
+}
+
+// isFunc reports whether fun is the function name of the easytmpl package, or any of its functions if name is empty.
+func isFunc(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	var id *ast.Ident
+	switch f := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return false
+	}
+	fn, ok := pass.TypesInfo.Uses[id].(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == pkgPath && (name == "" || fn.Name() == name) &&
+		fn.Type().(*types.Signature).Recv() == nil
+}
+
+// isMethod reports whether sel selects a method of easytmpl.Template.
+func isMethod(pass *analysis.Pass, sel *ast.SelectorExpr) bool {
+	fn, ok := pass.TypesInfo.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	t := recv.Type()
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Name() == "Template"
+}
+
+// constString returns the value of the constant string expression e.
+func constString(pass *analysis.Pass, e ast.Expr) (string, bool) {
+	tv, ok := pass.TypesInfo.Types[e]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
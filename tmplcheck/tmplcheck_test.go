@@ -0,0 +1,13 @@
+package tmplcheck_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/tylitianrui/easytmpl/tmplcheck"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), tmplcheck.Analyzer, "a")
+}